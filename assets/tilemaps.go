@@ -41,8 +41,14 @@ type TileMapTilesetJson struct {
 }
 
 type TileMapJson struct {
-	Layers   []TileMapLayerJson   `json:"layers"`
-	Tilesets []TileMapTilesetJson `json:"tilesets"`
+	Height     int                      `json:"height"`
+	Layers     []TileMapLayerJson       `json:"layers"`
+	NextId     constants.ID             `json:"nextobjectid"`
+	Properties []TileMapObjectPropsJson `json:"properties"`
+	TileHeight int                      `json:"tileheight"`
+	Tilesets   []TileMapTilesetJson     `json:"tilesets"`
+	TileWidth  int                      `json:"tilewidth"`
+	Width      int                      `json:"width"`
 }
 
 func (t *TileMapJson) GenTilesets() ([]Tileset, error) {
@@ -60,6 +66,16 @@ func (t *TileMapJson) GenTilesets() ([]Tileset, error) {
 	return ts, nil
 }
 
+// Props returns the map-level custom properties by name.
+func (t *TileMapJson) Props() map[string]any {
+	props := make(map[string]any)
+	for _, p := range t.Properties {
+		props[p.Name] = p.Value
+	}
+
+	return props
+}
+
 func NewTileMapJson(fp string) (*TileMapJson, error) {
 	contents, err := os.ReadFile(fp)
 	if err != nil {
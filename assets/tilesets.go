@@ -38,17 +38,25 @@ type UniformTilesetJson struct {
 }
 
 type DynamicTileset struct {
-	gid  constants.ID
-	imgs []*ebiten.Image
+	collisions [][]Point
+	gid        constants.ID
+	imgs       []*ebiten.Image
+	names      []string
 }
 
 type DynamicTilesetTile struct {
+	collision   []Point
 	id          constants.ID
 	image       string
 	imageHeight uint16
 	imageWidth  uint16
 }
 
+// Point is a position relative to the top-left corner of a tile's image.
+type Point struct {
+	X, Y float64
+}
+
 type DynamicTilesetJson struct {
 	Tiles []*DynamicTilesetTile `json:"tiles"`
 }
@@ -91,6 +99,27 @@ func (d *DynamicTileset) Type() TilesetType {
 	return DynamicType
 }
 
+// Collision returns the collision shape drawn on the tile in Tiled as a
+// polygon relative to the image's top-left corner, or nil if it has none.
+func (d *DynamicTileset) Collision(id constants.ID) []Point {
+	realId := id - d.gid
+
+	return d.collisions[realId]
+}
+
+// Len returns the number of tiles (images) held by the tileset.
+func (d *DynamicTileset) Len() int {
+	return len(d.imgs)
+}
+
+// Name returns the base file name, without extension, of the image backing
+// the given global ID (e.g. "castle_blue").
+func (d *DynamicTileset) Name(id constants.ID) string {
+	realId := id - d.gid
+
+	return d.names[realId]
+}
+
 func NewTileset(tp string, gid constants.ID) (Tileset, error) {
 	content, err := os.ReadFile(tp)
 	if err != nil {
@@ -140,7 +169,8 @@ func NewTileset(tp string, gid constants.ID) (Tileset, error) {
 		for i, t := range tilesSlice {
 			tileMap := t.(map[string]interface{})
 			convertedTiles[i] = &DynamicTilesetTile{
-				image: tileMap["image"].(string),
+				collision: parseCollision(tileMap["objectgroup"]),
+				image:     tileMap["image"].(string),
 			}
 		}
 
@@ -150,7 +180,9 @@ func NewTileset(tp string, gid constants.ID) (Tileset, error) {
 			},
 		}
 
+		collisions := make([][]Point, 0)
 		imgs := make([]*ebiten.Image, 0)
+		names := make([]string, 0)
 		for _, tile := range dT.Data.Tiles {
 			imgPath := filepath.Clean(tile.image)
 			imgPath = strings.ReplaceAll(imgPath, "\\", "/")
@@ -161,12 +193,16 @@ func NewTileset(tp string, gid constants.ID) (Tileset, error) {
 				return nil, fmt.Errorf("DynamicTileset: Unable to create image from file at path: (%s) -- Error: %w", imgPath, err)
 			}
 
+			collisions = append(collisions, tile.collision)
 			imgs = append(imgs, img)
+			names = append(names, strings.TrimSuffix(filepath.Base(imgPath), filepath.Ext(imgPath)))
 		}
 
 		tileset = &DynamicTileset{
-			gid:  gid,
-			imgs: imgs,
+			collisions: collisions,
+			gid:        gid,
+			imgs:       imgs,
+			names:      names,
 		}
 	}
 
@@ -176,3 +212,42 @@ func NewTileset(tp string, gid constants.ID) (Tileset, error) {
 
 	return tileset, nil
 }
+
+// parseCollision converts the first shape of a tile's collision object group
+// into a polygon. Rectangles are expanded into their four corners.
+func parseCollision(objectGroup any) []Point {
+	group, ok := objectGroup.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	objects, ok := group["objects"].([]interface{})
+	if !ok || len(objects) == 0 {
+		return nil
+	}
+
+	object := objects[0].(map[string]interface{})
+	x, _ := object["x"].(float64)
+	y, _ := object["y"].(float64)
+
+	if polygon, ok := object["polygon"].([]interface{}); ok {
+		points := make([]Point, 0, len(polygon))
+		for _, p := range polygon {
+			point := p.(map[string]interface{})
+			points = append(points, Point{
+				X: x + point["x"].(float64),
+				Y: y + point["y"].(float64),
+			})
+		}
+
+		return points
+	}
+
+	w, _ := object["width"].(float64)
+	h, _ := object["height"].(float64)
+	if w == 0 || h == 0 {
+		return nil
+	}
+
+	return []Point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}
+}
@@ -11,3 +11,26 @@ func NewCamera(x, y float64) *Camera {
 		Y: y,
 	}
 }
+
+// Pan moves the camera by the given world distance.
+func (c *Camera) Pan(dx, dy float64) {
+	c.X += dx
+	c.Y += dy
+}
+
+// Clamp keeps a view of the given size inside the world. A view larger than
+// the world is pinned to its top-left corner.
+func (c *Camera) Clamp(worldW, worldH, viewW, viewH float64) {
+	c.X = max(min(c.X, worldW-viewW), 0)
+	c.Y = max(min(c.Y, worldH-viewH), 0)
+}
+
+// ScreenToWorld converts a point on the screen to where it is in the world.
+func (c *Camera) ScreenToWorld(x, y float64) (float64, float64) {
+	return x + c.X, y + c.Y
+}
+
+// WorldToScreen converts a point in the world to where it's drawn on screen.
+func (c *Camera) WorldToScreen(x, y float64) (float64, float64) {
+	return x - c.X, y - c.Y
+}
@@ -0,0 +1,167 @@
+package campaign
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/sim"
+)
+
+// Campaign is an ordered run of missions, each unlocked by finishing earlier
+// ones.
+type Campaign struct {
+	Missions []Mission `json:"missions"`
+}
+
+// Mission is one map of the campaign and what the player has to do on it.
+type Mission struct {
+	Briefing   string          `json:"briefing"`
+	Id         string          `json:"id"`
+	Map        string          `json:"map"` // Path to the Tiled map
+	Name       string          `json:"name"`
+	Objectives []sim.Objective `json:"objectives"`
+	// Player is the side the player plays, blue if unset.
+	Player constants.Player `json:"player,omitempty"`
+	// Unlocks are the missions finishing this one makes available. If unset
+	// it's the next mission in order.
+	Unlocks []string `json:"unlocks,omitempty"`
+}
+
+// Progress is how far the player got through the campaign.
+type Progress struct {
+	Completed []string `json:"completed"`
+	Unlocked  []string `json:"unlocked"`
+}
+
+// Load reads the campaign at fp.
+func Load(fp string) (*Campaign, error) {
+	contents, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Campaign
+	if err := json.Unmarshal(contents, &c); err != nil {
+		return nil, fmt.Errorf("Unable to parse %s: %w", fp, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("%s is invalid: %w", fp, err)
+	}
+
+	return &c, nil
+}
+
+// Validate checks every mission can be played and unlocks missions that
+// exist.
+func (c *Campaign) Validate() error {
+	if len(c.Missions) == 0 {
+		return errors.New("Campaign has no missions")
+	}
+
+	for _, m := range c.Missions {
+		if m.Id == "" || m.Map == "" {
+			return fmt.Errorf("Mission %q needs an id and a map", m.Name)
+		}
+		if len(m.Objectives) == 0 {
+			return fmt.Errorf("Mission %q has no objectives", m.Id)
+		}
+		for _, o := range m.Objectives {
+			switch o.Type {
+			case constants.CAPTURE_OBJECTIVE, constants.SURVIVE_OBJECTIVE, constants.WIN_OBJECTIVE:
+			default:
+				return fmt.Errorf("Mission %q has an unknown objective %q", m.Id, o.Type)
+			}
+		}
+		for _, id := range m.Unlocks {
+			if c.Mission(id) == nil {
+				return fmt.Errorf("Mission %q unlocks %q, which doesn't exist", m.Id, id)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Complete records the mission as done and unlocks whatever it leads to.
+func (c *Campaign) Complete(p *Progress, id string) {
+	if !slices.Contains(p.Completed, id) {
+		p.Completed = append(p.Completed, id)
+	}
+
+	i := slices.IndexFunc(c.Missions, func(m Mission) bool {
+		return m.Id == id
+	})
+	if i < 0 {
+		return
+	}
+
+	unlocks := c.Missions[i].Unlocks
+	if len(unlocks) == 0 && i+1 < len(c.Missions) {
+		unlocks = []string{c.Missions[i+1].Id}
+	}
+	for _, u := range unlocks {
+		if !slices.Contains(p.Unlocked, u) {
+			p.Unlocked = append(p.Unlocked, u)
+		}
+	}
+}
+
+// Mission returns the mission with the given ID, or nil if there is none.
+func (c *Campaign) Mission(id string) *Mission {
+	for i := range c.Missions {
+		if c.Missions[i].Id == id {
+			return &c.Missions[i]
+		}
+	}
+
+	return nil
+}
+
+// Unlocked reports whether the mission can be played. The first mission
+// always can.
+func (c *Campaign) Unlocked(p *Progress, id string) bool {
+	return c.Missions[0].Id == id || slices.Contains(p.Unlocked, id)
+}
+
+// ReadProgress loads the progress at fp. A player who never played has made
+// no progress rather than hit an error.
+func ReadProgress(fp string) (*Progress, error) {
+	p := &Progress{
+		Completed: make([]string, 0),
+		Unlocked:  make([]string, 0),
+	}
+
+	contents, err := os.ReadFile(fp)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(contents, p); err != nil {
+		return nil, fmt.Errorf("Unable to parse %s: %w", fp, err)
+	}
+
+	return p, nil
+}
+
+// WriteProgress stores the progress at fp, creating its directory if needed.
+func WriteProgress(fp string, p *Progress) error {
+	contents, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fp, contents, 0o644)
+}
@@ -1,5 +1,7 @@
 package constants
 
+import "fmt"
+
 const (
 	Tilesize = 64
 )
@@ -25,6 +27,9 @@ const (
 	YELLOW Player = "YELLOW"
 )
 
+// Every player that can take part in a match
+var Players = []Player{BLUE, GREEN, RED, YELLOW}
+
 type LayerObjectName string
 
 const (
@@ -38,6 +43,106 @@ type LayerRenderableType string
 const (
 	BUILDING LayerRenderableType = "Building"
 	CLIFF    LayerRenderableType = "Cliff"
+	SQUAD    LayerRenderableType = "Squad"
 	STAIRS   LayerRenderableType = "Stairs"
 	TILE     LayerRenderableType = "Tile"
+	TRIGGER  LayerRenderableType = "Trigger"
+)
+
+// Buildings upgrade House -> Tower -> Castle, so the archetype of a building
+// doubles as its level.
+type BuildingLevel uint8
+
+const (
+	HOUSE_LEVEL BuildingLevel = iota + 1
+	TOWER_LEVEL
+	CASTLE_LEVEL
+)
+
+func (l BuildingLevel) String() string {
+	switch l {
+	case HOUSE_LEVEL:
+		return "House"
+	case TOWER_LEVEL:
+		return "Tower"
+	case CASTLE_LEVEL:
+		return "Castle"
+	}
+
+	return "Unknown"
+}
+
+// MarshalText lets levels key maps in JSON by name.
+func (l BuildingLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *BuildingLevel) UnmarshalText(text []byte) error {
+	for _, level := range []BuildingLevel{HOUSE_LEVEL, TOWER_LEVEL, CASTLE_LEVEL} {
+		if level.String() == string(text) {
+			*l = level
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unknown building level %q", text)
+}
+
+type UnitType string
+
+const (
+	ARCHER UnitType = "Archer"
+	KNIGHT UnitType = "Knight"
+	PAWN   UnitType = "Pawn"
+)
+
+// The order unit types are cycled through when picking what a building makes
+var UnitTypes = []UnitType{KNIGHT, ARCHER, PAWN}
+
+// What happens to reinforcements that don't fit in a building
+type OverflowRule string
+
+const (
+	BOUNCE OverflowRule = "Bounce" // March back to where they came from
+	KEEP   OverflowRule = "Keep"   // Stay as an over-capacity garrison that decays
+	WASTE  OverflowRule = "Waste"  // Disband
+)
+
+// Orders players can give the simulation
+type CommandType string
+
+const (
+	ABILITY_COMMAND CommandType = "Ability"
+	SEND_COMMAND    CommandType = "Send"
+	UNIT_COMMAND    CommandType = "Unit"
+	UPGRADE_COMMAND CommandType = "Upgrade"
+)
+
+type AbilityType string
+
+const (
+	FREEZE AbilityType = "Freeze" // Stop a building's production
+	HASTE  AbilityType = "Haste"  // Speed up all of a player's squads
+	HEAL   AbilityType = "Heal"   // Restore garrisons around a point
+)
+
+// Abilities in hotkey order, the first on 1
+var AbilityTypes = []AbilityType{HASTE, FREEZE, HEAL}
+
+// What an ability is cast on
+type AbilityTarget string
+
+const (
+	AREA_TARGET     AbilityTarget = "Area"
+	BUILDING_TARGET AbilityTarget = "Building"
+	GLOBAL_TARGET   AbilityTarget = "Global"
+)
+
+// What a campaign objective asks of the player
+type ObjectiveType string
+
+const (
+	CAPTURE_OBJECTIVE ObjectiveType = "Capture" // Take a building
+	SURVIVE_OBJECTIVE ObjectiveType = "Survive" // Last a number of seconds
+	WIN_OBJECTIVE     ObjectiveType = "Win"     // Win holding a number of buildings
 )
@@ -5,6 +5,8 @@ import (
 	"github.com/ehutchllew/autoarmy/constants"
 )
 
+// Building is how a building is drawn. Its gameplay fields mirror the
+// simulation's and are synced from it every update.
 type Building struct {
 	components.Coordinates
 	components.Dimensions
@@ -14,5 +16,17 @@ type Building struct {
 	Capacity   uint8
 	CapturedBy constants.Player
 	IsSpawn    bool
-	Occupancy  uint8
+	Level      constants.BuildingLevel
+	// NeutralRegen is how many troops per second the building regains while
+	// neutral, up to the garrison the map gave it.
+	NeutralRegen float64
+	Occupancy    uint8
+	// Rebel neutrals counter-attack nearby players.
+	Rebel bool
+	// Unit is the type of troops the building produces, a knight if unset.
+	Unit constants.UnitType
+	// UpgradeProgress is the completed fraction (0-1) of an in-flight
+	// upgrade and is only meaningful while `Upgrading` is set.
+	UpgradeProgress float64
+	Upgrading       bool
 }
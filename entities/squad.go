@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"github.com/ehutchllew/autoarmy/components"
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// Squad is how a marching squad is drawn. Its coordinates are the center of
+// the formation at its feet, between where the simulation last put it and
+// where it will be next tick.
+type Squad struct {
+	components.Coordinates
+	components.Renderable
+	Id     constants.ID
+	Owner  constants.Player
+	Troops uint8
+	Unit   constants.UnitType
+}
+
+// TransCoords returns the top-left corner of a single unit's sprite drawn at
+// the squad's position.
+func (s *Squad) TransCoords() (float64, float64) {
+	if s.Image == nil {
+		return s.X, s.Y
+	}
+
+	bounds := s.Image.Bounds()
+	return s.X - float64(bounds.Dx())/2, s.Y - float64(bounds.Dy())
+}
+
+func (s *Squad) Type() constants.LayerRenderableType {
+	return constants.SQUAD
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"github.com/ehutchllew/autoarmy/components"
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// Trigger is a scripted event placed on the map, either as an area or linked
+// to a building. It's never drawn, the simulation runs it. Conditions and
+// actions left unset are ignored.
+type Trigger struct {
+	components.Coordinates
+	components.Dimensions
+	components.LayerObject
+	components.Renderable
+	components.Transformable
+	Building constants.ID // The linked building, 0 if none
+	// CapturedBy is a condition: the linked building belongs to the player.
+	CapturedBy constants.Player
+	// EndMatch is an action: the player's team wins, nobody for NONE.
+	EndMatch constants.Player
+	// EnteredBy is a condition: a squad of the player is inside the area.
+	EnteredBy constants.Player
+	Message   string // An action: shown to everyone
+	// Reveal is an action: the fog lifts over the area or linked building
+	// for the player.
+	Reveal constants.Player
+	// SetOwner is an action: the linked building changes hands.
+	SetOwner constants.Player
+	// SpawnTroops is an action: a squad of `SpawnOwner` marches out of the
+	// linked building, or the area, toward `SpawnTarget`.
+	SpawnOwner  constants.Player
+	SpawnTarget constants.ID
+	SpawnTroops uint8
+	SpawnUnit   constants.UnitType
+	Tick        uint64 // A condition: the match has reached the tick
+	// TroopsBelow is a condition: `TroopsOf` has fewer troops than this.
+	TroopsBelow int
+	TroopsOf    constants.Player
+}
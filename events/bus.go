@@ -0,0 +1,66 @@
+package events
+
+// Bus is a typed, in-process publish/subscribe hub for scene events. Delivery
+// is synchronous: `Publish` doesn't return until every handler has run.
+// Events published from within a handler are queued and delivered once the
+// current event has reached all of its handlers, so every subscriber sees
+// events in the exact order they were published.
+type Bus struct {
+	dispatching bool
+	handlers    map[Kind][]*handler
+	queue       []Event
+}
+
+type handler struct {
+	fn func(Event)
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[Kind][]*handler),
+	}
+}
+
+// Subscribe registers `fn` to be called with every event of type E, after any
+// handlers already subscribed to it. The returned func unsubscribes it.
+func Subscribe[E Event](b *Bus, fn func(E)) func() {
+	var zero E
+	kind := zero.Kind()
+
+	h := &handler{
+		fn: func(e Event) {
+			fn(e.(E))
+		},
+	}
+	b.handlers[kind] = append(b.handlers[kind], h)
+
+	return func() {
+		hs := b.handlers[kind]
+		for i, registered := range hs {
+			if registered == h {
+				// Copy rather than splice in place, a dispatch may be
+				// ranging over the old slice right now
+				b.handlers[kind] = append(hs[:i:i], hs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (b *Bus) Publish(e Event) {
+	b.queue = append(b.queue, e)
+	if b.dispatching {
+		return
+	}
+
+	b.dispatching = true
+	for len(b.queue) > 0 {
+		next := b.queue[0]
+		b.queue = b.queue[1:]
+
+		for _, h := range b.handlers[next.Kind()] {
+			h.fn(next)
+		}
+	}
+	b.dispatching = false
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPublishOrder(t *testing.T) {
+	b := NewBus()
+	got := make([]string, 0)
+	Subscribe(b, func(e SquadArrived) {
+		got = append(got, "first")
+		// Published from a handler, so it waits until every handler has
+		// seen this arrival
+		if e.Squad == 1 {
+			b.Publish(SquadArrived{Squad: 2})
+		}
+	})
+	Subscribe(b, func(e SquadArrived) {
+		got = append(got, "second")
+	})
+	Subscribe(b, func(e MatchEnded) {
+		got = append(got, "ended")
+	})
+
+	b.Publish(SquadArrived{Squad: 1})
+	b.Publish(MatchEnded{})
+
+	want := []string{"first", "second", "first", "second", "ended"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Handlers ran %v, want %v", got, want)
+	}
+}
+
+func TestUnsubscribeDuringPublish(t *testing.T) {
+	b := NewBus()
+	got := make([]string, 0)
+	var unsubscribe func()
+	unsubscribe = Subscribe(b, func(e SquadArrived) {
+		got = append(got, "once")
+		unsubscribe()
+	})
+	Subscribe(b, func(e SquadArrived) {
+		got = append(got, "always")
+	})
+
+	b.Publish(SquadArrived{})
+	b.Publish(SquadArrived{})
+
+	// Unsubscribing mustn't make the handler after it miss the event
+	want := []string{"once", "always", "always"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Handlers ran %v, want %v", got, want)
+	}
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	b := NewBus()
+	b.Publish(MatchEnded{})
+
+	// The bus is left ready for the next publish
+	var ran bool
+	Subscribe(b, func(e MatchEnded) {
+		ran = true
+	})
+	b.Publish(MatchEnded{})
+	if !ran {
+		t.Errorf("Handler subscribed after an unheard publish didn't run")
+	}
+}
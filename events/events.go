@@ -0,0 +1,90 @@
+package events
+
+import "github.com/ehutchllew/autoarmy/constants"
+
+type Kind uint8
+
+const (
+	EntitySpawnedKind Kind = iota
+	EntityDestroyedKind
+	BuildingCapturedKind
+	OccupancyChangedKind
+	SquadDispatchedKind
+	SquadArrivedKind
+	MatchEndedKind
+	TriggerFiredKind
+	PlayerEliminatedKind
+)
+
+type Event interface {
+	Kind() Kind
+}
+
+type EntitySpawned struct {
+	Id    constants.ID
+	Owner constants.Player
+	Type  constants.LayerRenderableType
+}
+
+func (EntitySpawned) Kind() Kind { return EntitySpawnedKind }
+
+type EntityDestroyed struct {
+	Id   constants.ID
+	Type constants.LayerRenderableType
+}
+
+func (EntityDestroyed) Kind() Kind { return EntityDestroyedKind }
+
+type BuildingCaptured struct {
+	Building constants.ID
+	From     constants.Player
+	To       constants.Player
+}
+
+func (BuildingCaptured) Kind() Kind { return BuildingCapturedKind }
+
+type OccupancyChanged struct {
+	Building constants.ID
+	From     uint8
+	To       uint8
+}
+
+func (OccupancyChanged) Kind() Kind { return OccupancyChangedKind }
+
+type SquadDispatched struct {
+	Owner  constants.Player
+	Source constants.ID
+	Squad  constants.ID
+	Target constants.ID
+	Troops uint8
+}
+
+func (SquadDispatched) Kind() Kind { return SquadDispatchedKind }
+
+type SquadArrived struct {
+	Owner  constants.Player
+	Squad  constants.ID
+	Target constants.ID
+	Troops uint8
+}
+
+func (SquadArrived) Kind() Kind { return SquadArrivedKind }
+
+type MatchEnded struct {
+	Winners []constants.Player
+}
+
+func (MatchEnded) Kind() Kind { return MatchEndedKind }
+
+type TriggerFired struct {
+	Message string // Shown to everyone, if set
+	Trigger constants.ID
+}
+
+func (TriggerFired) Kind() Kind { return TriggerFiredKind }
+
+type PlayerEliminated struct {
+	Player constants.Player
+}
+
+func (PlayerEliminated) Kind() Kind { return PlayerEliminatedKind }
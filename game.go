@@ -11,11 +11,16 @@ type Game struct {
 }
 
 func NewGame() *Game {
-	activeSceneId := scenes.GameSceneId
+	activeSceneId := scenes.StartSceneId
+	result := &scenes.MatchResult{}
+	setup := &scenes.MatchSetup{}
 	sceneMap := map[scenes.SceneId]scenes.Scene{
-		scenes.GameSceneId: scenes.NewGameScene(),
+		scenes.GameSceneId:    scenes.NewGameScene(setup, result),
+		scenes.ResultsSceneId: scenes.NewResultsScene(result),
+		scenes.StartSceneId:   scenes.NewStartScene(setup),
 	}
 	sceneMap[activeSceneId].FirstLoad()
+	sceneMap[activeSceneId].OnEnter()
 
 	return &Game{
 		activeSceneId,
@@ -24,7 +29,22 @@ func NewGame() *Game {
 }
 
 func (g *Game) Update() error {
-	g.sceneMap[g.activeSceneId].Update()
+	nextSceneId := g.sceneMap[g.activeSceneId].Update()
+	if nextSceneId == scenes.ExitSceneId {
+		return ebiten.Termination
+	}
+
+	if nextSceneId != g.activeSceneId {
+		g.sceneMap[g.activeSceneId].OnExit()
+
+		next := g.sceneMap[nextSceneId]
+		if !next.IsLoaded() {
+			next.FirstLoad()
+		}
+		next.OnEnter()
+		g.activeSceneId = nextSceneId
+	}
+
 	return nil
 }
 
@@ -0,0 +1,296 @@
+package navigation
+
+import (
+	"math"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// Side is a bitmask of the four edges of a cell.
+type Side uint8
+
+const (
+	NorthSide Side = 1 << iota
+	EastSide
+	SouthSide
+	WestSide
+)
+
+func SideOf(dir constants.CardinalDirection) Side {
+	switch dir {
+	case constants.NORTH:
+		return NorthSide
+	case constants.EAST:
+		return EastSide
+	case constants.SOUTH:
+		return SouthSide
+	case constants.WEST:
+		return WestSide
+	}
+
+	return 0
+}
+
+// Opposite returns the matching edge(s) of the neighboring cell(s).
+func (s Side) Opposite() Side {
+	var o Side
+	if s&NorthSide != 0 {
+		o |= SouthSide
+	}
+	if s&EastSide != 0 {
+		o |= WestSide
+	}
+	if s&SouthSide != 0 {
+		o |= NorthSide
+	}
+	if s&WestSide != 0 {
+		o |= EastSide
+	}
+
+	return o
+}
+
+type Point struct {
+	X, Y float64
+}
+
+type Cell struct {
+	// Building is the ID of the building whose footprint covers the cell, or
+	// 0 if there is none. Covered cells are never walkable.
+	Building  constants.ID
+	Edges     Side // Edges that can't be crossed, in either direction
+	Elevation uint8
+	Solid     bool
+	Stairs    *Stairs
+}
+
+// Stairs are the only cells where units may change elevation. `Ascend` is the
+// side units come in from to climb, i.e. the foot of the stairs, and `Descend`
+// the side they come in from to go down.
+type Stairs struct {
+	Ascend  constants.CardinalDirection
+	Descend constants.CardinalDirection
+}
+
+// Grid is the per-cell walkability of a map. It's built once at load time and
+// is the single source of truth for where units may stand and step.
+type Grid struct {
+	Height   int
+	TileSize float64
+	Width    int
+	cells    []Cell
+}
+
+func NewGrid(width, height int, tileSize float64) *Grid {
+	return &Grid{
+		Height:   height,
+		TileSize: tileSize,
+		Width:    width,
+		cells:    make([]Cell, width*height),
+	}
+}
+
+// AddFootprint marks every cell whose center lies inside the world-space
+// polygon as covered by the building.
+func (g *Grid) AddFootprint(building constants.ID, polygon []Point) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range polygon {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+
+	x0, y0 := g.CellAt(minX, minY)
+	x1, y1 := g.CellAt(maxX, maxY)
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			c := g.Cell(x, y)
+			if c == nil {
+				continue
+			}
+
+			cX, cY := g.CellCenter(x, y)
+			if pointInPolygon(cX, cY, polygon) {
+				c.Building = building
+			}
+		}
+	}
+}
+
+// BlockEdges prevents movement across the given sides of a cell. The matching
+// side of each neighbor is blocked too, so the edge is closed both ways.
+func (g *Grid) BlockEdges(x, y int, sides Side) {
+	c := g.Cell(x, y)
+	if c == nil {
+		return
+	}
+	c.Edges |= sides
+
+	for _, side := range []Side{NorthSide, EastSide, SouthSide, WestSide} {
+		if sides&side == 0 {
+			continue
+		}
+
+		nX, nY := Neighbor(x, y, side)
+		if n := g.Cell(nX, nY); n != nil {
+			n.Edges |= side.Opposite()
+		}
+	}
+}
+
+// CanStep reports whether a unit may move from one cell to an orthogonally
+// adjacent one. Elevation only changes by a single level, on stairs and in
+// their direction. Diagonal steps are allowed only if both of the orthogonal
+// steps around the corner are.
+func (g *Grid) CanStep(fromX, fromY, toX, toY int) bool {
+	dX, dY := toX-fromX, toY-fromY
+	if dX < -1 || dX > 1 || dY < -1 || dY > 1 || (dX == 0 && dY == 0) {
+		return false
+	}
+	if dX != 0 && dY != 0 {
+		return g.CanStep(fromX, fromY, toX, fromY) && g.CanStep(toX, fromY, toX, toY) &&
+			g.CanStep(fromX, fromY, fromX, toY) && g.CanStep(fromX, toY, toX, toY)
+	}
+
+	if !g.Walkable(fromX, fromY) || !g.Walkable(toX, toY) {
+		return false
+	}
+
+	side := StepSide(dX, dY)
+	from, to := g.Cell(fromX, fromY), g.Cell(toX, toY)
+	if from.Edges&side != 0 {
+		return false
+	}
+
+	switch {
+	case from.Elevation == to.Elevation:
+		return true
+	case to.Elevation == from.Elevation+1:
+		return from.climbs(side, true) || to.climbs(side, true)
+	case from.Elevation == to.Elevation+1:
+		return from.climbs(side, false) || to.climbs(side, false)
+	}
+
+	return false
+}
+
+// climbs reports whether a step in the direction of `side` goes up (or down)
+// the cell's stairs, whether it's stepping onto or off of them.
+func (c *Cell) climbs(side Side, up bool) bool {
+	if c.Stairs == nil {
+		return false
+	}
+
+	entry := c.Stairs.Descend
+	if up {
+		entry = c.Stairs.Ascend
+	}
+
+	return SideOf(entry).Opposite() == side
+}
+
+// Cell returns the cell at the given grid coordinates, or nil if they're out
+// of bounds.
+func (g *Grid) Cell(x, y int) *Cell {
+	if !g.InBounds(x, y) {
+		return nil
+	}
+
+	return &g.cells[y*g.Width+x]
+}
+
+// CellAt converts world coordinates to grid coordinates.
+func (g *Grid) CellAt(wX, wY float64) (int, int) {
+	return int(math.Floor(wX / g.TileSize)), int(math.Floor(wY / g.TileSize))
+}
+
+// CellCenter converts grid coordinates to the world coordinates of the
+// cell's center.
+func (g *Grid) CellCenter(x, y int) (float64, float64) {
+	return (float64(x) + 0.5) * g.TileSize, (float64(y) + 0.5) * g.TileSize
+}
+
+func (g *Grid) ElevationAt(wX, wY float64) uint8 {
+	c := g.Cell(g.CellAt(wX, wY))
+	if c == nil {
+		return 0
+	}
+
+	return c.Elevation
+}
+
+func (g *Grid) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.Width && y < g.Height
+}
+
+func (g *Grid) SetElevation(x, y int, elevation uint8) {
+	if c := g.Cell(x, y); c != nil {
+		c.Elevation = elevation
+	}
+}
+
+func (g *Grid) SetSolid(x, y int) {
+	if c := g.Cell(x, y); c != nil {
+		c.Solid = true
+	}
+}
+
+func (g *Grid) SetStairs(x, y int, ascend, descend constants.CardinalDirection) {
+	if c := g.Cell(x, y); c != nil {
+		c.Stairs = &Stairs{
+			Ascend:  ascend,
+			Descend: descend,
+		}
+	}
+}
+
+// Walkable reports whether a unit may stand in the cell at all.
+func (g *Grid) Walkable(x, y int) bool {
+	c := g.Cell(x, y)
+
+	return c != nil && !c.Solid && c.Building == 0
+}
+
+// Neighbor returns the coordinates of the cell across the given side.
+func Neighbor(x, y int, side Side) (int, int) {
+	switch side {
+	case NorthSide:
+		return x, y - 1
+	case EastSide:
+		return x + 1, y
+	case SouthSide:
+		return x, y + 1
+	case WestSide:
+		return x - 1, y
+	}
+
+	return x, y
+}
+
+// StepSide returns the side of a cell crossed by an orthogonal step.
+func StepSide(dX, dY int) Side {
+	switch {
+	case dY < 0:
+		return NorthSide
+	case dX > 0:
+		return EastSide
+	case dY > 0:
+		return SouthSide
+	case dX < 0:
+		return WestSide
+	}
+
+	return 0
+}
+
+func pointInPolygon(x, y float64, polygon []Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Y > y) != (b.Y > y) && x < (b.X-a.X)*(y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
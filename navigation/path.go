@@ -0,0 +1,270 @@
+package navigation
+
+import (
+	"cmp"
+	"container/heap"
+	"math"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// Neighbor offsets in the order they're searched, which keeps ties, and so
+// the paths found, the same from run to run.
+var steps = [8][2]int{
+	{0, -1}, {1, 0}, {0, 1}, {-1, 0},
+	{1, -1}, {1, 1}, {-1, 1}, {-1, -1},
+}
+
+// Endpoint is where a path starts or ends: a point on the map or, when
+// `Building` is set, the building there, which units reach through the
+// walkable cells around its footprint.
+type Endpoint struct {
+	Building constants.ID
+	X, Y     float64
+}
+
+// FindPath searches the grid with A* for the shortest walk between two
+// endpoints, honoring blocked edges, building footprints and stairs. Each
+// endpoint is snapped to the walkable cell nearest its point that still has
+// a way through, which for a building is one next to its footprint. It
+// returns where the walk starts and the waypoints to follow from there, the
+// last one being where it ends, or false if there is no way through.
+func (g *Grid) FindPath(from, to Endpoint) (Point, []Point, bool) {
+	starts, goals := g.candidates(from), g.candidates(to)
+
+	// Leave from the nearest cell with a way to any of the goals, and arrive
+	// at the nearest goal reachable from there
+	start, ok := first(starts, g.reachable(goals, true))
+	if !ok {
+		return Point{}, nil, false
+	}
+	goal, ok := first(goals, g.reachable([]int{start}, false))
+	if !ok {
+		return Point{}, nil, false
+	}
+
+	cells, ok := g.search(start, goal)
+	if !ok {
+		return Point{}, nil, false
+	}
+
+	return g.snap(start, from), g.waypoints(cells, g.snap(goal, to)), true
+}
+
+// candidates returns the cells the endpoint may be snapped to, nearest its
+// point first: the walkable cells bordering the building's footprint, the
+// point's own cell if it's walkable, or else every walkable cell.
+func (g *Grid) candidates(e Endpoint) []int {
+	cells := make([]int, 0)
+	if e.Building != 0 {
+		door := make([]bool, len(g.cells))
+		for idx, c := range g.cells {
+			if c.Building != e.Building {
+				continue
+			}
+
+			for _, side := range []Side{NorthSide, EastSide, SouthSide, WestSide} {
+				nX, nY := Neighbor(idx%g.Width, idx/g.Width, side)
+				if g.Walkable(nX, nY) {
+					door[nY*g.Width+nX] = true
+				}
+			}
+		}
+		for idx, isDoor := range door {
+			if isDoor {
+				cells = append(cells, idx)
+			}
+		}
+	}
+
+	if len(cells) == 0 {
+		if x, y := g.CellAt(e.X, e.Y); g.Walkable(x, y) {
+			return []int{y*g.Width + x}
+		}
+		for idx := range g.cells {
+			if g.Walkable(idx%g.Width, idx/g.Width) {
+				cells = append(cells, idx)
+			}
+		}
+	}
+
+	distance := func(idx int) float64 {
+		x, y := g.CellCenter(idx%g.Width, idx/g.Width)
+		return math.Hypot(x-e.X, y-e.Y)
+	}
+	slices.SortStableFunc(cells, func(a, b int) int {
+		return cmp.Compare(distance(a), distance(b))
+	})
+
+	return cells
+}
+
+// estimate is the octile distance between two cells, which never overshoots
+// the cost of walking it.
+func (g *Grid) estimate(from, to int) float64 {
+	dX := math.Abs(float64(to%g.Width - from%g.Width))
+	dY := math.Abs(float64(to/g.Width - from/g.Width))
+
+	return g.TileSize * (max(dX, dY) + (math.Sqrt2-1)*min(dX, dY))
+}
+
+// first returns the first of the cells that's marked in `allowed`.
+func first(cells []int, allowed []bool) (int, bool) {
+	for _, idx := range cells {
+		if allowed[idx] {
+			return idx, true
+		}
+	}
+
+	return 0, false
+}
+
+// reachable floods the grid from the cells and marks every cell that can be
+// walked to from them or, in `reverse`, that can walk to them.
+func (g *Grid) reachable(from []int, reverse bool) []bool {
+	seen := make([]bool, len(g.cells))
+	queue := slices.Clone(from)
+	for _, idx := range from {
+		seen[idx] = true
+	}
+
+	for len(queue) > 0 {
+		idx := queue[0]
+		queue = queue[1:]
+
+		x, y := idx%g.Width, idx/g.Width
+		for _, step := range steps {
+			nX, nY := x+step[0], y+step[1]
+			if !g.InBounds(nX, nY) || seen[nY*g.Width+nX] {
+				continue
+			}
+
+			if (reverse && !g.CanStep(nX, nY, x, y)) || (!reverse && !g.CanStep(x, y, nX, nY)) {
+				continue
+			}
+			seen[nY*g.Width+nX] = true
+			queue = append(queue, nY*g.Width+nX)
+		}
+	}
+
+	return seen
+}
+
+// search runs A* between two cells and returns the cells walked, from the
+// goal back to the start.
+func (g *Grid) search(start, goal int) ([]int, bool) {
+	cost := make(map[int]float64, len(g.cells))
+	came := make(map[int]int, len(g.cells))
+	cost[start] = 0
+
+	open := &pathQueue{}
+	heap.Push(open, pathNode{cell: start, estimate: g.estimate(start, goal)})
+	for open.Len() > 0 {
+		node := heap.Pop(open).(pathNode)
+		if node.cell == goal {
+			cells := []int{goal}
+			for cell := goal; cell != start; {
+				cell = came[cell]
+				cells = append(cells, cell)
+			}
+
+			return cells, true
+		}
+		if node.cost > cost[node.cell] {
+			continue // Superseded by a cheaper way here
+		}
+
+		x, y := node.cell%g.Width, node.cell/g.Width
+		for _, step := range steps {
+			nX, nY := x+step[0], y+step[1]
+			if !g.CanStep(x, y, nX, nY) {
+				continue
+			}
+
+			next := nY*g.Width + nX
+			nextCost := node.cost + g.TileSize*math.Hypot(float64(step[0]), float64(step[1]))
+			if known, ok := cost[next]; ok && known <= nextCost {
+				continue
+			}
+
+			cost[next] = nextCost
+			came[next] = node.cell
+			heap.Push(open, pathNode{
+				cell:     next,
+				cost:     nextCost,
+				estimate: nextCost + g.estimate(next, goal),
+				order:    open.pushed,
+			})
+		}
+	}
+
+	return nil, false
+}
+
+// snap returns the endpoint's own point if it lies in the cell, or else the
+// cell's center.
+func (g *Grid) snap(idx int, e Endpoint) Point {
+	if x, y := g.CellAt(e.X, e.Y); y*g.Width+x == idx && g.InBounds(x, y) {
+		return Point{X: e.X, Y: e.Y}
+	}
+
+	x, y := g.CellCenter(idx%g.Width, idx/g.Width)
+	return Point{X: x, Y: y}
+}
+
+// waypoints keeps only the cells where the walk, given from the goal back,
+// turns and ends on `end` rather than the goal's center.
+func (g *Grid) waypoints(cells []int, end Point) []Point {
+	path := make([]Point, 0)
+	for i := len(cells) - 2; i > 0; i-- {
+		prev, cell, next := cells[i+1], cells[i], cells[i-1]
+		if cell-prev == next-cell {
+			continue // Still heading the same way
+		}
+
+		x, y := g.CellCenter(cell%g.Width, cell/g.Width)
+		path = append(path, Point{X: x, Y: y})
+	}
+
+	return append(path, end)
+}
+
+type pathNode struct {
+	cell     int
+	cost     float64
+	estimate float64
+	order    int
+}
+
+// pathQueue is a min-heap of nodes by estimated total cost, the oldest first
+// among equals.
+type pathQueue struct {
+	nodes  []pathNode
+	pushed int
+}
+
+func (q *pathQueue) Len() int { return len(q.nodes) }
+
+func (q *pathQueue) Less(i, j int) bool {
+	a, b := q.nodes[i], q.nodes[j]
+	if a.estimate != b.estimate {
+		return a.estimate < b.estimate
+	}
+
+	return a.order < b.order
+}
+
+func (q *pathQueue) Swap(i, j int) { q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i] }
+
+func (q *pathQueue) Push(x any) {
+	q.nodes = append(q.nodes, x.(pathNode))
+	q.pushed++
+}
+
+func (q *pathQueue) Pop() any {
+	last := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+
+	return last
+}
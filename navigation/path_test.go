@@ -0,0 +1,187 @@
+package navigation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+const testTileSize = 64
+
+// plateauGrid is 5x5 cells with the top two rows raised a level and stairs
+// in the middle of the row below them.
+func plateauGrid(ascend, descend constants.CardinalDirection) *Grid {
+	g := NewGrid(5, 5, testTileSize)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 5; x++ {
+			g.SetElevation(x, y, 1)
+		}
+	}
+	g.SetStairs(2, 2, ascend, descend)
+	g.BlockEdges(2, 2, EastSide|WestSide)
+
+	return g
+}
+
+func center(x, y int) Endpoint {
+	return Endpoint{
+		X: (float64(x) + 0.5) * testTileSize,
+		Y: (float64(y) + 0.5) * testTileSize,
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name string
+		grid func() *Grid
+		from Endpoint
+		to   Endpoint
+		ok   bool
+		// length is the least the walk can be, when it has to go around
+		length float64
+	}{
+		{
+			name: "level",
+			grid: func() *Grid { return NewGrid(5, 5, testTileSize) },
+			from: center(0, 4),
+			to:   center(4, 4),
+			ok:   true,
+		},
+		{
+			name: "climbs stairs from the ascend side",
+			grid: func() *Grid { return plateauGrid(constants.SOUTH, constants.NORTH) },
+			from: center(2, 4),
+			to:   center(2, 0),
+			ok:   true,
+		},
+		{
+			name: "descends stairs from the descend side",
+			grid: func() *Grid { return plateauGrid(constants.SOUTH, constants.NORTH) },
+			from: center(0, 0),
+			to:   center(0, 4),
+			ok:   true,
+			// Over to the stairs and back
+			length: 4 * testTileSize,
+		},
+		{
+			name: "refuses to climb stairs from the other side",
+			grid: func() *Grid { return plateauGrid(constants.NORTH, constants.SOUTH) },
+			from: center(2, 4),
+			to:   center(2, 0),
+			ok:   false,
+		},
+		{
+			name: "refuses to climb without stairs",
+			grid: func() *Grid {
+				g := NewGrid(5, 5, testTileSize)
+				g.SetElevation(2, 0, 1)
+				return g
+			},
+			from: center(2, 4),
+			to:   center(2, 0),
+			ok:   false,
+		},
+		{
+			name: "goes around cliff edges",
+			grid: func() *Grid {
+				g := NewGrid(5, 5, testTileSize)
+				for y := 0; y < 4; y++ {
+					g.BlockEdges(2, y, EastSide)
+				}
+				return g
+			},
+			from: center(2, 0),
+			to:   center(3, 0),
+			ok:   true,
+			// Down, across and back up
+			length: 7 * testTileSize,
+		},
+		{
+			name: "unreachable goal",
+			grid: func() *Grid {
+				g := NewGrid(5, 5, testTileSize)
+				g.BlockEdges(4, 4, NorthSide|EastSide|SouthSide|WestSide)
+				return g
+			},
+			from: center(0, 0),
+			to:   center(4, 4),
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.grid()
+			start, path, ok := g.FindPath(tt.from, tt.to)
+			if ok != tt.ok {
+				t.Fatalf("FindPath() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if start != (Point{X: tt.from.X, Y: tt.from.Y}) {
+				t.Errorf("FindPath() starts at %v, want %v", start, tt.from)
+			}
+			if end := path[len(path)-1]; end != (Point{X: tt.to.X, Y: tt.to.Y}) {
+				t.Errorf("FindPath() ends at %v, want %v", end, tt.to)
+			}
+			if length := walkedLength(start, path); length < tt.length-1e-9 {
+				t.Errorf("FindPath() walks %.1f, want at least %.1f", length, tt.length)
+			}
+			assertWalkable(t, g, start, path)
+		})
+	}
+}
+
+func TestFindPathSnapsToBuildingDoor(t *testing.T) {
+	g := NewGrid(5, 5, testTileSize)
+	g.AddFootprint(1, []Point{{X: 128, Y: 64}, {X: 192, Y: 64}, {X: 192, Y: 128}, {X: 128, Y: 128}})
+	// The building's entrance lies in a nook closed on every other side
+	g.BlockEdges(2, 2, EastSide|SouthSide|WestSide)
+
+	from := Endpoint{Building: 1, X: 160, Y: 144}
+	start, path, ok := g.FindPath(from, center(2, 4))
+	if !ok {
+		t.Fatal("FindPath() found no way out of the building")
+	}
+
+	if x, y := g.CellAt(start.X, start.Y); x == 2 && y == 2 {
+		t.Errorf("FindPath() starts in the closed nook at (%d, %d)", x, y)
+	}
+	assertWalkable(t, g, start, path)
+}
+
+// assertWalkable checks every cell the path crosses can be stepped into from
+// the one before it.
+func assertWalkable(t *testing.T, g *Grid, start Point, path []Point) {
+	t.Helper()
+
+	prevX, prevY := g.CellAt(start.X, start.Y)
+	from := start
+	for _, p := range path {
+		dist := math.Hypot(p.X-from.X, p.Y-from.Y)
+		for d := 0.0; d <= dist; d += testTileSize / 8 {
+			x, y := g.CellAt(from.X+(p.X-from.X)*d/dist, from.Y+(p.Y-from.Y)*d/dist)
+			if x == prevX && y == prevY {
+				continue
+			}
+			if !g.CanStep(prevX, prevY, x, y) {
+				t.Fatalf("path steps from (%d, %d) to (%d, %d)", prevX, prevY, x, y)
+			}
+			prevX, prevY = x, y
+		}
+		from = p
+	}
+}
+
+func walkedLength(from Point, path []Point) float64 {
+	var length float64
+	for _, p := range path {
+		length += math.Hypot(p.X-from.X, p.Y-from.Y)
+		from = p
+	}
+
+	return length
+}
@@ -0,0 +1,427 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// MapPropertyPrefix marks the map-level Tiled properties that override rules,
+// e.g. `rules.dispatch.sendRatio`. The rest of the name is the path to the
+// value in the rules file.
+const MapPropertyPrefix = "rules."
+
+// Rules are every balance number a match is played with. They're loaded from
+// the rules file, adjusted by the map, and stored with saves so a match always
+// resumes under the rules it started with.
+type Rules struct {
+	Abilities  map[constants.AbilityType]AbilityStats `json:"abilities"`
+	Arrival    ArrivalRules                           `json:"arrival"`
+	Combat     CombatRules                            `json:"combat"`
+	Dispatch   DispatchRules                          `json:"dispatch"`
+	Economy    EconomyRules                           `json:"economy"`
+	Levels     map[constants.BuildingLevel]LevelStats `json:"levels"`
+	Neutral    NeutralRules                           `json:"neutral"`
+	Production ProductionRules                        `json:"production"`
+	Units      map[constants.UnitType]UnitStats       `json:"units"`
+	Vision     VisionRules                            `json:"vision"`
+}
+
+type AbilityStats struct {
+	Cooldown float64 `json:"cooldown"` // Seconds
+	Duration float64 `json:"duration"` // Seconds the effect lasts, if it lasts
+	GoldCost int     `json:"goldCost"`
+	// Magnitude is the strength of the effect: a speed multiplier for
+	// haste, or troops restored per building for heal.
+	Magnitude float64                 `json:"magnitude"`
+	Radius    float64                 `json:"radius"` // Tiles, for area abilities
+	Target    constants.AbilityTarget `json:"target"`
+}
+
+type ArrivalRules struct {
+	// Overflow decides what happens to reinforcements beyond the target's
+	// capacity.
+	Overflow constants.OverflowRule `json:"overflow"`
+}
+
+type CombatRules struct {
+	BaseTowerRange float64 `json:"baseTowerRange"` // Tiles
+	// CaptureSurvivors is the least troops left garrisoning a building after
+	// it's captured, however close the fight was.
+	CaptureSurvivors uint8 `json:"captureSurvivors"`
+	// ElevationDefense is the extra defense multiplier per elevation level a
+	// building stands on.
+	ElevationDefense float64 `json:"elevationDefense"`
+	// EngageRadius is how close (in pixels) two hostile squads have to get
+	// before they fight in the field.
+	EngageRadius float64 `json:"engageRadius"`
+	// MinAttack is the floor for the attack multiplier no matter how far
+	// uphill an attack goes.
+	MinAttack float64 `json:"minAttack"`
+	// PlateauTowerRange is the extra tower range, in tiles, per elevation
+	// level the tower stands on.
+	PlateauTowerRange float64 `json:"plateauTowerRange"`
+	// TowerFireInterval is the seconds between a tower's shots.
+	TowerFireInterval float64 `json:"towerFireInterval"`
+	// UphillPenalty is how much of its attack a squad loses per elevation
+	// level it has to climb from its source to the target.
+	UphillPenalty float64 `json:"uphillPenalty"`
+}
+
+type DispatchRules struct {
+	// SendRatio is the share of the source's occupancy sent with each order.
+	SendRatio  float64 `json:"sendRatio"`
+	SquadSpeed float64 `json:"squadSpeed"` // Pixels per second
+	// StaggerDelay is the gap in seconds between squads leaving together on a
+	// single multi-building order.
+	StaggerDelay float64 `json:"staggerDelay"`
+}
+
+type EconomyRules struct {
+	GoldPerHouse float64 `json:"goldPerHouse"` // Gold per second per house owned
+	StartingGold int     `json:"startingGold"`
+}
+
+type LevelStats struct {
+	Capacity       uint8   `json:"capacity"`
+	Defense        float64 `json:"defense"`
+	GoldCost       int     `json:"goldCost"`       // Gold spent to reach this level
+	ProductionRate float64 `json:"productionRate"` // Troops per second
+	UpgradeCost    uint8   `json:"upgradeCost"`    // Troops spent from `Occupancy` to reach this level
+	UpgradeTime    float64 `json:"upgradeTime"`    // Seconds
+	Vision         float64 `json:"vision"`         // Sight radius in tiles
+}
+
+// NeutralRules apply to every neutral building on the map. Buildings can also
+// opt into regeneration and rebelling on their own through the map.
+type NeutralRules struct {
+	// RebelInterval is the seconds between a rebel's counter-attacks.
+	RebelInterval float64 `json:"rebelInterval"`
+	// RebelMinGarrison is the least troops a rebel keeps home; it won't
+	// attack with fewer.
+	RebelMinGarrison uint8   `json:"rebelMinGarrison"`
+	RebelRange       float64 `json:"rebelRange"` // Tiles
+	Rebels           bool    `json:"rebels"`     // Whether every neutral is a rebel
+	// RegenRate is how many troops per second neutral buildings regain
+	// toward their map garrison. 0 turns regeneration off.
+	RegenRate float64 `json:"regenRate"`
+}
+
+type ProductionRules struct {
+	// DecayRate is how many troops per second a building above its capacity
+	// loses until it's back down to it.
+	DecayRate float64 `json:"decayRate"`
+	// SpawnBonus multiplies the production rate of `IsSpawn` buildings.
+	SpawnBonus float64 `json:"spawnBonus"`
+}
+
+type UnitStats struct {
+	Attack float64 `json:"attack"`
+	// Counters multiplies the unit's attack against the given unit types.
+	// Types it isn't listed against fight at 1.
+	Counters map[constants.UnitType]float64 `json:"counters"`
+	Defense  float64                        `json:"defense"`
+	GoldCost int                            `json:"goldCost"` // Gold to switch a building to this type
+	Speed    float64                        `json:"speed"`    // Multiple of the dispatch squad speed
+}
+
+type VisionRules struct {
+	// ElevationBonus is the extra sight radius, in tiles, per elevation level
+	// a building or squad stands on.
+	ElevationBonus float64 `json:"elevationBonus"`
+	SquadRadius    float64 `json:"squadRadius"` // Tiles
+}
+
+// Default returns the rules the game is balanced around. The rules file ships
+// with every one of them for designers to tune, and is kept the same as these
+// by the tests; maps only list what they change.
+func Default() *Rules {
+	return &Rules{
+		Abilities: map[constants.AbilityType]AbilityStats{
+			constants.FREEZE: {
+				Cooldown: 60,
+				Duration: 15,
+				GoldCost: 20,
+				Target:   constants.BUILDING_TARGET,
+			},
+			constants.HASTE: {
+				Cooldown:  45,
+				Duration:  10,
+				GoldCost:  15,
+				Magnitude: 1.5,
+				Target:    constants.GLOBAL_TARGET,
+			},
+			constants.HEAL: {
+				Cooldown:  60,
+				GoldCost:  25,
+				Magnitude: 5,
+				Radius:    3,
+				Target:    constants.AREA_TARGET,
+			},
+		},
+		Arrival: ArrivalRules{
+			Overflow: constants.KEEP,
+		},
+		Combat: CombatRules{
+			BaseTowerRange:    3,
+			CaptureSurvivors:  1,
+			ElevationDefense:  0.25,
+			EngageRadius:      32,
+			MinAttack:         0.25,
+			PlateauTowerRange: 1,
+			TowerFireInterval: 2,
+			UphillPenalty:     0.25,
+		},
+		Dispatch: DispatchRules{
+			SendRatio:    0.5,
+			SquadSpeed:   96,
+			StaggerDelay: 0.4,
+		},
+		Economy: EconomyRules{
+			GoldPerHouse: 1,
+			StartingGold: 10,
+		},
+		Levels: map[constants.BuildingLevel]LevelStats{
+			constants.HOUSE_LEVEL: {
+				Capacity:       10,
+				Defense:        1.0,
+				ProductionRate: 0.5,
+				Vision:         3,
+			},
+			constants.TOWER_LEVEL: {
+				Capacity:       20,
+				Defense:        1.5,
+				GoldCost:       20,
+				ProductionRate: 0.75,
+				UpgradeCost:    5,
+				UpgradeTime:    10,
+				Vision:         5,
+			},
+			constants.CASTLE_LEVEL: {
+				Capacity:       40,
+				Defense:        2.0,
+				GoldCost:       50,
+				ProductionRate: 1.0,
+				UpgradeCost:    15,
+				UpgradeTime:    20,
+				Vision:         4,
+			},
+		},
+		Neutral: NeutralRules{
+			RebelInterval:    20,
+			RebelMinGarrison: 4,
+			RebelRange:       8,
+		},
+		Production: ProductionRules{
+			DecayRate:  1.0,
+			SpawnBonus: 1.5,
+		},
+		// Knights ride down archers, archers shoot pawns, pawns' spears stop
+		// knights
+		Units: map[constants.UnitType]UnitStats{
+			constants.ARCHER: {
+				Attack: 1.2,
+				Counters: map[constants.UnitType]float64{
+					constants.KNIGHT: 0.75,
+					constants.PAWN:   1.5,
+				},
+				Defense:  0.8,
+				GoldCost: 15,
+				Speed:    0.9,
+			},
+			constants.KNIGHT: {
+				Attack: 1,
+				Counters: map[constants.UnitType]float64{
+					constants.ARCHER: 1.5,
+					constants.PAWN:   0.75,
+				},
+				Defense: 1,
+				Speed:   1,
+			},
+			constants.PAWN: {
+				Attack: 0.8,
+				Counters: map[constants.UnitType]float64{
+					constants.ARCHER: 0.75,
+					constants.KNIGHT: 1.5,
+				},
+				Defense:  1,
+				GoldCost: 5,
+				Speed:    1.25,
+			},
+		},
+		Vision: VisionRules{
+			ElevationBonus: 1,
+			SquadRadius:    2.5,
+		},
+	}
+}
+
+// Load reads the rules file at fp on top of the defaults. Sections and levels
+// only need the values they change.
+func Load(fp string) (*Rules, error) {
+	contents, err := os.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch map[string]any
+	if err := json.Unmarshal(contents, &patch); err != nil {
+		return nil, fmt.Errorf("Unable to parse rules file %s: %w", fp, err)
+	}
+
+	r := Default()
+	if err := r.merge(patch); err != nil {
+		return nil, fmt.Errorf("Unable to load rules file %s: %w", fp, err)
+	}
+
+	return r, nil
+}
+
+// ApplyOverrides sets every `rules.`-prefixed property to its value, addressed
+// by its path in the rules file. Properties without the prefix are ignored.
+func (r *Rules) ApplyOverrides(props map[string]any) error {
+	patch := make(map[string]any)
+	for name, value := range props {
+		path, ok := strings.CutPrefix(name, MapPropertyPrefix)
+		if !ok {
+			continue
+		}
+
+		keys := strings.Split(path, ".")
+		section := patch
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := section[key].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				section[key] = next
+			}
+			section = next
+		}
+		section[keys[len(keys)-1]] = value
+	}
+
+	if err := r.merge(patch); err != nil {
+		return fmt.Errorf("Unable to apply rule overrides: %w", err)
+	}
+
+	return nil
+}
+
+// Clone returns a deep copy, so a match can't change the rules it was started
+// from.
+func (r *Rules) Clone() *Rules {
+	contents, err := json.Marshal(r)
+	if err != nil {
+		panic(err) // Rules are always representable as JSON
+	}
+
+	clone := &Rules{}
+	if err := json.Unmarshal(contents, clone); err != nil {
+		panic(err)
+	}
+
+	return clone
+}
+
+// Validate rejects rules that would break a match rather than just unbalance
+// it.
+func (r *Rules) Validate() error {
+	switch r.Arrival.Overflow {
+	case constants.BOUNCE, constants.KEEP, constants.WASTE:
+	default:
+		return fmt.Errorf("Unknown overflow rule %q", r.Arrival.Overflow)
+	}
+	// Assaults divide by the multipliers, so they can't reach zero
+	if r.Combat.ElevationDefense < 0 {
+		return fmt.Errorf("Elevation defense can't be negative, got %v", r.Combat.ElevationDefense)
+	}
+	if r.Combat.MinAttack <= 0 {
+		return fmt.Errorf("Min attack must be positive, got %v", r.Combat.MinAttack)
+	}
+	if r.Dispatch.SendRatio <= 0 || r.Dispatch.SendRatio > 1 {
+		return fmt.Errorf("Send ratio must be in (0, 1], got %v", r.Dispatch.SendRatio)
+	}
+	if r.Dispatch.SquadSpeed <= 0 {
+		return fmt.Errorf("Squad speed must be positive, got %v", r.Dispatch.SquadSpeed)
+	}
+	if _, ok := r.Levels[constants.HOUSE_LEVEL]; !ok {
+		return fmt.Errorf("Rules are missing the %s level", constants.HOUSE_LEVEL)
+	}
+	for level, stats := range r.Levels {
+		if stats.Defense <= 0 {
+			return fmt.Errorf("%s defense must be positive, got %v", level, stats.Defense)
+		}
+	}
+	for ability, stats := range r.Abilities {
+		switch stats.Target {
+		case constants.AREA_TARGET, constants.BUILDING_TARGET, constants.GLOBAL_TARGET:
+		default:
+			return fmt.Errorf("%s has an unknown target %q", ability, stats.Target)
+		}
+	}
+	if _, ok := r.Units[constants.KNIGHT]; !ok {
+		return fmt.Errorf("Rules are missing the %s unit", constants.KNIGHT)
+	}
+	for unit, stats := range r.Units {
+		if stats.Attack <= 0 || stats.Defense <= 0 || stats.Speed <= 0 {
+			return fmt.Errorf("%s attack, defense and speed must be positive", unit)
+		}
+		for against, counter := range stats.Counters {
+			if counter <= 0 {
+				return fmt.Errorf("%s counter against %s must be positive, got %v", unit, against, counter)
+			}
+		}
+	}
+
+	return nil
+}
+
+// merge deep-merges the patch into the rules, so a patch only has to list the
+// values it changes. Unknown rules are rejected rather than silently ignored.
+func (r *Rules) merge(patch map[string]any) error {
+	contents, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(contents, &tree); err != nil {
+		return err
+	}
+	mergeTree(tree, patch)
+
+	contents, err = json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+
+	merged := &Rules{}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(merged); err != nil {
+		return err
+	}
+	if err := merged.Validate(); err != nil {
+		return err
+	}
+	*r = *merged
+
+	return nil
+}
+
+func mergeTree(tree, patch map[string]any) {
+	for key, value := range patch {
+		section, isSection := value.(map[string]any)
+		existing, exists := tree[key].(map[string]any)
+		if isSection && exists {
+			mergeTree(existing, section)
+			continue
+		}
+
+		tree[key] = value
+	}
+}
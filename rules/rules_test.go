@@ -0,0 +1,27 @@
+package rules
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestRulesFileMatchesDefault keeps the rules file designers tune listing
+// every rule, at exactly the value the game is balanced around.
+func TestRulesFileMatchesDefault(t *testing.T) {
+	contents, err := os.ReadFile("../assets/rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read on its own rather than on top of the defaults, so a rule missing
+	// from the file shows up too
+	var r Rules
+	if err := json.Unmarshal(contents, &r); err != nil {
+		t.Fatalf("Unable to parse rules file: %v", err)
+	}
+	if !reflect.DeepEqual(&r, Default()) {
+		t.Errorf("assets/rules.json differs from Default(), update one to match the other")
+	}
+}
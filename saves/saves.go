@@ -0,0 +1,125 @@
+package saves
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/rules"
+	"github.com/ehutchllew/autoarmy/sim"
+)
+
+// Version is bumped whenever a change to the format would make older saves
+// or replays load wrong.
+const Version = 9
+
+// Save is a match in progress. It carries the rules the match was started
+// with, so loading it doesn't pick up later edits to the rules file or map.
+type Save struct {
+	Handicaps sim.Handicaps `json:"handicaps"`
+	Map       string        `json:"map"` // Path to the Tiled map the match is played on
+	// Mission is the ID of the campaign mission being played, if any.
+	Mission    string           `json:"mission,omitempty"`
+	Objectives []sim.Objective  `json:"objectives"`
+	Player     constants.Player `json:"player"`
+	Rules      rules.Rules      `json:"rules"`
+	Seed       uint64           `json:"seed"`
+	State      sim.State        `json:"state"`
+	Teams      sim.Teams        `json:"teams"`
+	Version    int              `json:"version"`
+}
+
+// Replay is a whole match as the commands given in it. Starting the same map
+// with the same rules and seed and applying them on the same ticks plays it
+// out again.
+type Replay struct {
+	Commands  []sim.Command `json:"commands"`
+	Handicaps sim.Handicaps `json:"handicaps"`
+	Map       string        `json:"map"`
+	// Mission is the ID of the campaign mission played, if any.
+	Mission    string           `json:"mission,omitempty"`
+	Objectives []sim.Objective  `json:"objectives"`
+	Player     constants.Player `json:"player"`
+	Rules      rules.Rules      `json:"rules"`
+	Seed       uint64           `json:"seed"`
+	Teams      sim.Teams        `json:"teams"`
+	Version    int              `json:"version"`
+}
+
+// Read loads the save at fp, refusing ones written by an incompatible version.
+func Read(fp string) (*Save, error) {
+	var save Save
+	if err := read(fp, &save); err != nil {
+		return nil, err
+	}
+	if err := check(fp, save.Version, &save.Rules); err != nil {
+		return nil, err
+	}
+
+	return &save, nil
+}
+
+// ReadReplay loads the replay at fp, refusing ones written by an incompatible
+// version.
+func ReadReplay(fp string) (*Replay, error) {
+	var replay Replay
+	if err := read(fp, &replay); err != nil {
+		return nil, err
+	}
+	if err := check(fp, replay.Version, &replay.Rules); err != nil {
+		return nil, err
+	}
+
+	return &replay, nil
+}
+
+// Write stores the save at fp, creating its directory if needed.
+func Write(fp string, save *Save) error {
+	save.Version = Version
+	return write(fp, save)
+}
+
+// WriteReplay stores the replay at fp, creating its directory if needed.
+func WriteReplay(fp string, replay *Replay) error {
+	replay.Version = Version
+	return write(fp, replay)
+}
+
+func check(fp string, version int, r *rules.Rules) error {
+	if version != Version {
+		return fmt.Errorf("%s is version %d, expected %d", fp, version, Version)
+	}
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("%s has invalid rules: %w", fp, err)
+	}
+
+	return nil
+}
+
+func read(fp string, v any) error {
+	contents, err := os.ReadFile(fp)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(contents, v); err != nil {
+		return fmt.Errorf("Unable to parse %s: %w", fp, err)
+	}
+
+	return nil
+}
+
+func write(fp string, v any) error {
+	contents, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fp, contents, 0o644)
+}
@@ -0,0 +1,122 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Hotkeys for `constants.AbilityTypes`, in the same order
+var abilityKeys = []ebiten.Key{ebiten.KeyDigit1, ebiten.KeyDigit2, ebiten.KeyDigit3}
+
+// processAbilityKeys arms the ability whose hotkey was pressed, so the next
+// click casts it. Pressing the same hotkey again, Escape or the right mouse
+// button disarms it.
+func (g *GameScene) processAbilityKeys() {
+	for i, key := range abilityKeys {
+		if i >= len(constants.AbilityTypes) || !inpututil.IsKeyJustPressed(key) {
+			continue
+		}
+
+		ability := constants.AbilityTypes[i]
+		if g.casting == ability {
+			g.casting = ""
+			continue
+		}
+		g.casting = ability
+		g.boxing, g.dragSource = false, nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsMouseButtonJustPressed(ebiten.MouseButton2) {
+		g.casting = ""
+	}
+}
+
+// castAt casts the armed ability at the clicked point. Building abilities
+// need a building under the cursor, otherwise the ability stays armed.
+func (g *GameScene) castAt(x, y float64) {
+	cmd := sim.Command{
+		Ability: g.casting,
+		Player:  g.player,
+		Type:    constants.ABILITY_COMMAND,
+		X:       x,
+		Y:       y,
+	}
+
+	switch g.sim.Rules.Abilities[g.casting].Target {
+	case constants.BUILDING_TARGET:
+		b := g.buildingAt(x, y)
+		if b == nil {
+			return
+		}
+		cmd.Target = b.Id
+	case constants.GLOBAL_TARGET:
+		cmd.X, cmd.Y = 0, 0
+	}
+
+	g.sim.Enqueue(cmd)
+	g.casting = ""
+}
+
+// drawAbilityTarget outlines what the armed ability would hit: its area
+// around the cursor, or the building under it.
+func (g *GameScene) drawAbilityTarget(screen *ebiten.Image) {
+	if g.casting == "" {
+		return
+	}
+
+	clr := color.RGBA{120, 200, 255, 200}
+	cX, cY := g.cursorWorld()
+	stats := g.sim.Rules.Abilities[g.casting]
+	switch stats.Target {
+	case constants.AREA_TARGET:
+		radius := stats.Radius * constants.Tilesize
+		vector.DrawFilledCircle(screen, float32(cX), float32(cY), float32(radius), color.RGBA{120, 200, 255, 40}, true)
+		vector.StrokeCircle(screen, float32(cX), float32(cY), float32(radius), 2, clr, true)
+	case constants.BUILDING_TARGET:
+		if b := g.buildingAt(cX, cY); b != nil {
+			r := entityBounds(b)
+			vector.StrokeRect(screen, float32(r.MinX), float32(r.MinY), float32(r.MaxX-r.MinX), float32(r.MaxY-r.MinY), 2, clr, false)
+		}
+	}
+}
+
+// drawAbilities lists the abilities with their hotkeys, costs and cooldowns
+// under the gold in the top-left corner of the screen.
+func (g *GameScene) drawAbilities(screen *ebiten.Image, y float64) {
+	for i, ability := range constants.AbilityTypes {
+		stats, ok := g.sim.Rules.Abilities[ability]
+		if !ok || i >= len(abilityKeys) {
+			continue
+		}
+
+		clr := color.Color(color.White)
+		label := fmt.Sprintf("%d %s %dg", i+1, ability, stats.GoldCost)
+		switch cooldown := g.sim.Abilities.Cooldown(g.player, ability); {
+		case cooldown > 0:
+			label += fmt.Sprintf(" (%.0fs)", math.Ceil(cooldown))
+			clr = color.Gray{128}
+		case g.sim.Economy.Balance(g.player) < stats.GoldCost:
+			clr = color.Gray{128}
+		}
+		if g.casting == ability {
+			clr = color.RGBA{120, 200, 255, 255}
+		}
+
+		textW, textH := text.Measure(label, fontFace, 0)
+		vector.DrawFilledRect(screen, 8, float32(y), float32(textW+16), float32(textH+8), hudBackground, false)
+
+		tOpts := &text.DrawOptions{}
+		tOpts.GeoM.Translate(16, y+4)
+		tOpts.ColorScale.ScaleWithColor(clr)
+		text.Draw(screen, label, fontFace, tOpts)
+		y += textH + 8
+	}
+}
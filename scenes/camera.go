@@ -0,0 +1,47 @@
+package scenes
+
+import (
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	panSpeed = 900 // Pixels per second
+	// The cursor within this many pixels of the window's edge pans toward it
+	panEdge = 16
+)
+
+// panCamera moves the camera with the arrow keys or the cursor at the edge of
+// the window. It runs on real time, so it keeps working while paused.
+func (g *GameScene) panCamera(dt float64) {
+	var dx, dy float64
+	cX, cY := g.Cursor.Position()
+	viewW, viewH := ebiten.WindowSize()
+
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || (cX <= panEdge && cX >= 0) {
+		dx--
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || (cX >= viewW-panEdge && cX < viewW) {
+		dx++
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) || (cY <= panEdge && cY >= 0) {
+		dy--
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) || (cY >= viewH-panEdge && cY < viewH) {
+		dy++
+	}
+
+	g.camera.Pan(dx*panSpeed*dt, dy*panSpeed*dt)
+	g.camera.Clamp(
+		float64(g.tileMapJson.Width*constants.Tilesize),
+		float64(g.tileMapJson.Height*constants.Tilesize),
+		float64(viewW),
+		float64(viewH),
+	)
+}
+
+// cursorWorld returns where in the world the cursor is pointing.
+func (g *GameScene) cursorWorld() (float64, float64) {
+	cX, cY := g.Cursor.Position()
+	return g.camera.ScreenToWorld(float64(cX), float64(cY))
+}
@@ -0,0 +1,60 @@
+package scenes
+
+import (
+	"image/color"
+
+	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	exploredFog   = color.RGBA{0, 0, 0, 90}
+	unexploredFog = color.RGBA{0, 0, 0, 170}
+)
+
+// displayed returns the entity as the local player should see it. Buildings
+// out of sight are shown as they were last seen rather than as they are.
+func (g *GameScene) displayed(o entities.IEntity) entities.IEntity {
+	b, ok := o.(*entities.Building)
+	if !ok || g.sim.Vision.BuildingVisible(g.player, g.sim.Building(b.Id)) {
+		return o
+	}
+
+	known, ok := g.sim.Vision.Fog.LastKnown(g.player, b.Id)
+	if !ok {
+		return o
+	}
+
+	shown := *b
+	shown.Capacity = known.Capacity
+	shown.CapturedBy = known.Owner
+	shown.Level = known.Level
+	shown.Occupancy = known.Occupancy
+	shown.Unit = known.Unit
+	shown.Upgrading = false
+	g.sprites.Apply(&shown)
+
+	return &shown
+}
+
+// drawFog darkens every cell the local player can't currently see, more so
+// for the ones they've never seen.
+func (g *GameScene) drawFog(screen *ebiten.Image) {
+	fog := g.sim.Vision.Fog
+	size := float32(fog.TileSize)
+	for y := 0; y < fog.Height; y++ {
+		for x := 0; x < fog.Width; x++ {
+			cX, cY := (float64(x)+0.5)*fog.TileSize, (float64(y)+0.5)*fog.TileSize
+			if fog.Visible(g.player, cX, cY) {
+				continue
+			}
+
+			clr := exploredFog
+			if !fog.Explored(g.player, cX, cY) {
+				clr = unexploredFog
+			}
+			vector.DrawFilledRect(screen, float32(x)*size, float32(y)*size, size, size, clr, false)
+		}
+	}
+}
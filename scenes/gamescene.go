@@ -2,10 +2,12 @@ package scenes
 
 import (
 	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
 	"image/color"
 	"log"
+	"math/rand/v2"
 	"slices"
 	"strconv"
 
@@ -14,23 +16,63 @@ import (
 	"github.com/ehutchllew/autoarmy/components"
 	"github.com/ehutchllew/autoarmy/constants"
 	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/rules"
 	"github.com/ehutchllew/autoarmy/services"
+	"github.com/ehutchllew/autoarmy/sim"
+	"github.com/ehutchllew/autoarmy/spatial"
 	"github.com/ehutchllew/autoarmy/utils"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 type GameScene struct {
 	*services.Cursor
-	camera        *cameras.Camera
+	// accumulator is the time rendered since the last simulation tick.
+	accumulator float64
+	buildings   []*entities.Building
+	camera      *cameras.Camera
+	boxing      bool    // Whether a selection box is being dragged
+	boxX, boxY  float64 // Where the selection box was started
+	// casting is the ability armed to be cast on the next click, if any.
+	casting       constants.AbilityType
+	dragSource    *entities.Building // Where a send order being dragged starts
+	index         *spatial.Grid[entities.IEntity]
 	interactables *LayeredObjects
+	loaded        bool
+	mapPath       string
+	messages      []message
+	mission       string // ID of the campaign mission played, if any
+	paused        bool
+	player        constants.Player // The local player
 	renderables   *LayeredObjects
-	tileMapJson   *assets.TileMapJson
-	tilesets      []assets.Tileset
+	// replay holds the commands of a replay being played back that are yet
+	// to be applied. Input is ignored while `replaying`.
+	replay    []sim.Command
+	replaying bool
+	result    *MatchResult
+	// routes are the ways found between pairs of buildings, which never change
+	// during a match since buildings don't move.
+	routes      map[[2]constants.ID]*sim.Route
+	selected    []*entities.Building // Our buildings that send orders go out from
+	setup       *MatchSetup          // What the start scene picked to play
+	sim         *sim.Sim
+	speed       float64 // Multiple of real time the simulation runs at
+	sprites     *buildingSprites
+	squads      map[constants.ID]*entities.Squad
+	tileMapJson *assets.TileMapJson
+	tilesets    []assets.Tileset
+	triggers    []*entities.Trigger
+	world       *ebiten.Image // The whole map, before the camera is applied
 }
 
+const rulesPath = "./assets/rules.json"
+
 var (
+	bannerImgs map[constants.Player]*ebiten.Image
 	fontSource *text.GoTextFaceSource
 	fontFace   *text.GoTextFace
 )
@@ -39,7 +81,22 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{120, 180, 255, 255})
 	opts := ebiten.DrawImageOptions{}
 
-	g.drawMap(screen, &opts)
+	// The map is drawn in world space and then shown through the camera
+	g.world.Clear()
+	g.drawMap(g.world, &opts)
+	g.drawSquads(g.world)
+	g.drawFog(g.world)
+	g.drawSelection(g.world)
+	g.drawDragLine(g.world)
+	g.drawAbilityTarget(g.world)
+	opts.GeoM.Translate(g.camera.WorldToScreen(0, 0))
+	screen.DrawImage(g.world, &opts)
+
+	g.drawTooltip(screen)
+	g.drawHUD(screen)
+	g.drawMessages(screen)
+	g.drawSpeed(screen)
+	g.drawObjectives(screen)
 	g.Cursor.Draw(screen)
 }
 
@@ -55,49 +112,289 @@ func (g *GameScene) FirstLoad() {
 		Size:   16,
 	}
 
-	tileMapJson, err := assets.NewTileMapJson("./assets/maps/map1.json")
+	bannerImgs = make(map[constants.Player]*ebiten.Image)
+	for player, path := range map[constants.Player]string{
+		constants.BLUE: "./assets/ui/ribbon_blue.png",
+		constants.NONE: "./assets/ui/ribbon_gray.png",
+		constants.RED:  "./assets/ui/ribbon_red.png",
+	} {
+		img, _, err := ebitenutil.NewImageFromFile(path)
+		if err != nil {
+			log.Fatalf("Unable to parse image: %v", err)
+		}
+		bannerImgs[player] = img
+	}
+
+	unitImgs = loadUnitImgs()
+
+	if save := g.setup.Save; save != nil {
+		g.resumeMatch(save)
+		g.setup.Save = nil
+		g.loaded = true
+		return
+	}
+
+	g.mapPath = g.setup.Map
+	g.mission = ""
+	player := constants.BLUE
+	var objectives []sim.Objective
+	if m := g.setup.Mission; m != nil {
+		g.mapPath = m.Map
+		g.mission = m.Id
+		objectives = m.Objectives
+		if m.Player != "" {
+			player = m.Player
+		}
+	}
+
+	tileMapJson := g.loadMap(g.mapPath)
+	r, err := rules.Load(rulesPath)
 	if err != nil {
-		log.Fatalf("Unable to load Tilemap JSON: %v", err)
+		log.Fatalf("Unable to load rules: %v", err)
+	}
+	if err := r.ApplyOverrides(tileMapJson.Props()); err != nil {
+		log.Fatalf("Unable to apply map rules: %v", err)
 	}
 
+	teamsProp, _ := tileMapJson.Props()["teams"].(string)
+	teams, err := sim.ParseTeams(teamsProp)
+	if err != nil {
+		log.Fatalf("Unable to parse map teams: %v", err)
+	}
+	handicaps, err := sim.ParseHandicaps(tileMapJson.Props())
+	if err != nil {
+		log.Fatalf("Unable to parse map handicaps: %v", err)
+	}
+
+	g.startMatch(tileMapJson, sim.Setup{
+		Handicaps:  handicaps,
+		Objectives: objectives,
+		Player:     player,
+		Rules:      r,
+		Seed:       rand.Uint64(),
+		Teams:      teams,
+	})
+	g.loaded = true
+}
+
+// startMatch sets up a fresh match on the map. The setup decides who plays,
+// under which rules and with what seed; everything else comes from the map.
+func (g *GameScene) startMatch(tileMapJson *assets.TileMapJson, setup sim.Setup) {
 	tilesets, err := tileMapJson.GenTilesets()
 	if err != nil {
 		log.Fatalf("Unable to load tilesets: %v", err)
 	}
 
+	g.accumulator = 0
+	g.buildings = make([]*entities.Building, 0)
+	g.boxing = false
+	g.casting = ""
+	g.messages = nil
+	g.dragSource = nil
+	g.replay = nil
+	g.replaying = false
+	g.routes = make(map[[2]constants.ID]*sim.Route)
+	g.selected = make([]*entities.Building, 0)
+	g.squads = make(map[constants.ID]*entities.Squad)
+
 	g.camera = cameras.NewCamera(0.0, 0.0)
+	if g.world != nil {
+		g.world.Deallocate()
+	}
+	g.world = ebiten.NewImage(tileMapJson.Width*constants.Tilesize, tileMapJson.Height*constants.Tilesize)
+	g.paused = false
+	g.player = setup.Player
 	g.tileMapJson = tileMapJson
 	g.tilesets = tilesets
-	g.renderables, g.interactables = g.firstLoadObjectState()
+	g.sprites = newBuildingSprites(tilesets)
+	g.renderables, g.interactables, g.triggers, err = g.firstLoadObjectState()
+	if err != nil {
+		log.Fatalf("Unable to load map objects: %v", err)
+	}
+
+	g.index = spatial.NewGrid[entities.IEntity](constants.Tilesize * 2)
+	setup.Buildings = make([]sim.Building, 0)
+	setup.NextId = tileMapJson.NextId
+	setup.Triggers = make([]sim.Trigger, 0)
+	for _, z := range g.interactables.LayerZIndices {
+		for _, o := range g.interactables.Objects[z] {
+			g.index.Insert(o, entityBounds(o))
+
+			b, ok := o.(*entities.Building)
+			if !ok || slices.Contains(g.buildings, b) {
+				continue
+			}
+
+			b.Level = g.sprites.Level(b.Gid)
+			g.buildings = append(g.buildings, b)
+			setup.Buildings = append(setup.Buildings, sim.Building{
+				Capacity:   b.Capacity,
+				CapturedBy: b.CapturedBy,
+				Garrison:   b.Occupancy,
+				Id:         b.Id,
+				IsSpawn:    b.IsSpawn,
+				Level:      b.Level,
+				Occupancy:  b.Occupancy,
+				Rebel:      b.Rebel,
+				Regen:      b.NeutralRegen,
+				Unit:       b.Unit,
+				X:          b.X + float64(b.Width)/2,
+				Y:          b.Y,
+			})
+		}
+	}
+
+	// Triggers fire in map order whatever layer they're on
+	slices.SortFunc(g.triggers, func(a, b *entities.Trigger) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	for _, t := range g.triggers {
+		setup.Triggers = append(setup.Triggers, sim.Trigger{
+			Actions: sim.TriggerActions{
+				EndMatch:    t.EndMatch,
+				Message:     t.Message,
+				Reveal:      t.Reveal,
+				SetOwner:    t.SetOwner,
+				SpawnOwner:  t.SpawnOwner,
+				SpawnTarget: t.SpawnTarget,
+				SpawnTroops: t.SpawnTroops,
+				SpawnUnit:   t.SpawnUnit,
+			},
+			Building: t.Building,
+			Conditions: sim.TriggerConditions{
+				CapturedBy:  t.CapturedBy,
+				EnteredBy:   t.EnteredBy,
+				Tick:        t.Tick,
+				TroopsBelow: t.TroopsBelow,
+				TroopsOf:    t.TroopsOf,
+			},
+			Height: float64(t.Height),
+			Id:     t.Id,
+			Width:  float64(t.Width),
+			X:      t.X,
+			Y:      t.Y,
+		})
+	}
+
+	setup.Nav = g.buildNavGrid()
+	g.sim = sim.New(setup)
+
+	events.Subscribe(g.sim.Bus, func(e events.BuildingCaptured) {
+		if b := g.building(e.Building); b != nil && e.From == g.player {
+			g.deselect(b)
+		}
+	})
+	events.Subscribe(g.sim.Bus, func(e events.PlayerEliminated) {
+		g.showMessage(fmt.Sprintf("%s has been eliminated", e.Player))
+	})
+	events.Subscribe(g.sim.Bus, func(e events.TriggerFired) {
+		if e.Message != "" {
+			g.showMessage(e.Message)
+		}
+	})
+	events.Subscribe(g.sim.Bus, func(e events.MatchEnded) {
+		g.result.Eliminated = slices.Clone(g.sim.Match.Eliminated)
+		g.result.Mission = g.mission
+		g.result.MissionComplete = g.sim.Objectives.Complete(g.sim.Buildings)
+		g.result.Player = g.player
+		g.result.Winners = slices.Clone(e.Winners)
+	})
+
+	g.sim.Start()
+	g.sync()
 }
 
 func (g *GameScene) IsLoaded() bool {
-	panic("unimplemented")
+	return g.loaded
 }
 
 func (g *GameScene) OnEnter() {
-	panic("unimplemented")
+	ebiten.SetCursorMode(ebiten.CursorModeHidden)
 }
 
+// OnExit leaves the match behind. A finished match can't be resumed and one
+// left for the menu is saved, so either way the next visit loads anew.
 func (g *GameScene) OnExit() {
-	panic("unimplemented")
+	g.loaded = false
 }
 
+// Update turns input into commands and advances the simulation by however
+// many fixed ticks fit in the time since the last update, scaled by the game
+// speed. Nothing but the simulation stops while paused.
 func (g *GameScene) Update() SceneId {
+	dt := 1 / float64(ebiten.TPS())
+
 	g.Cursor.Update()
-	clicked := ebiten.IsMouseButtonPressed(ebiten.MouseButton0)
-	if clicked {
-		cX, cY := g.Cursor.Position()
-		fmt.Printf("\nMouse Clicked::(%d,%d)\n", cX, cY)
-		g.processMouseClick(float64(cX), float64(cY))
+	g.panCamera(dt)
+	g.updateMessages(dt)
+	g.processSpeedKeys()
+	cX, cY := g.cursorWorld()
+	if !g.replaying {
+		g.processAbilityKeys()
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButton0) {
+			fmt.Printf("\nMouse Clicked::(%.0f,%.0f)\n", cX, cY)
+			if g.casting != "" {
+				g.castAt(cX, cY)
+			} else {
+				g.processMouseClick(cX, cY)
+			}
+		}
+		if inpututil.IsMouseButtonJustReleased(ebiten.MouseButton0) {
+			g.processMouseRelease(cX, cY)
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+			g.processUpgradeKey()
+		}
+		if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+			g.processUnitKey()
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) {
+		g.playReplay()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		g.saveMatch()
+		return StartSceneId
+	}
+
+	if !g.paused {
+		g.accumulator += dt * g.speed
+	}
+	for g.accumulator >= sim.TickDuration {
+		g.accumulator -= sim.TickDuration
+		for len(g.replay) > 0 && g.replay[0].Tick == g.sim.Tick {
+			g.sim.Enqueue(g.replay[0])
+			g.replay = g.replay[1:]
+		}
+
+		if g.sim.Step() {
+			g.sync()
+			g.writeReplay()
+			g.recordMission()
+			return ResultsSceneId
+		}
 	}
+	g.sync()
+
 	return GameSceneId
 }
 
+// building returns the building with the given ID, or nil if there is none.
+func (g *GameScene) building(id constants.ID) *entities.Building {
+	for _, b := range g.buildings {
+		if b.Id == id {
+			return b
+		}
+	}
+
+	return nil
+}
+
 func (g *GameScene) drawMap(screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
 	for i := 0; i < len(g.renderables.LayerZIndices)-1; i++ {
 		objects := g.renderables.Objects[uint8(i)]
 		for _, o := range objects {
+			o = g.displayed(o)
 			opts.GeoM.Translate(o.TransCoords())
 			screen.DrawImage(o.Img(), opts)
 			opts.GeoM.Reset()
@@ -106,7 +403,10 @@ func (g *GameScene) drawMap(screen *ebiten.Image, opts *ebiten.DrawImageOptions)
 	}
 }
 
-func (g *GameScene) firstLoadObjectState() (*LayeredObjects, *LayeredObjects) {
+// firstLoadObjectState builds every object on the map. Triggers are kept
+// apart, since they're neither drawn nor interacted with, and a malformed one
+// fails the load rather than leaving the map without its script.
+func (g *GameScene) firstLoadObjectState() (*LayeredObjects, *LayeredObjects, []*entities.Trigger, error) {
 	layerZIndices := make([]uint8, len(g.tileMapJson.Layers))
 	renderables := &LayeredObjects{
 		Objects: make(map[uint8][]entities.IEntity),
@@ -114,6 +414,7 @@ func (g *GameScene) firstLoadObjectState() (*LayeredObjects, *LayeredObjects) {
 	interactables := &LayeredObjects{
 		Objects: make(map[uint8][]entities.IEntity),
 	}
+	triggers := make([]*entities.Trigger, 0)
 
 	for _, layer := range g.tileMapJson.Layers {
 		z, err := strconv.ParseUint(layer.ZIndex, 10, 8)
@@ -206,10 +507,17 @@ func (g *GameScene) firstLoadObjectState() (*LayeredObjects, *LayeredObjects) {
 			// Assign object and its properties to a struct
 			object, err := assignObject(obj, tileset)
 			if err != nil {
+				if obj.Type == string(constants.TRIGGER) {
+					return nil, nil, nil, fmt.Errorf("Unable to load trigger (%d): %w", obj.Id, err)
+				}
 				// FIXME: #1 in `todo.txt` (convert to tile layer)
 				// fmt.Printf("Unable to unpack object :: Error: \n %v", err)
 				continue
 			}
+			if t, ok := object.(*entities.Trigger); ok {
+				triggers = append(triggers, t)
+				continue
+			}
 
 			renderables.Objects[currentZ] = append(renderables.Objects[currentZ], object)
 			interactables.Objects[currentZ] = append(interactables.Objects[currentZ], object)
@@ -217,31 +525,154 @@ func (g *GameScene) firstLoadObjectState() (*LayeredObjects, *LayeredObjects) {
 	}
 	renderables.LayerZIndices = layerZIndices
 	interactables.LayerZIndices = layerZIndices
-	return renderables, interactables
+	return renderables, interactables, triggers, nil
+}
+
+// objectAt returns the interactable whose image covers the given point, or
+// nil if there is none. When images overlap the one drawn in front (lowest on
+// screen) wins.
+func (g *GameScene) objectAt(x, y float64) entities.IEntity {
+	var found entities.IEntity
+	var foundBottom float64
+	for _, o := range g.index.QueryPoint(x, y) {
+		if s, ok := o.(*entities.Squad); ok && !g.squadVisible(s) {
+			continue
+		}
+
+		bounds, _ := g.index.Bounds(o)
+		if found == nil || bounds.MaxY > foundBottom {
+			found, foundBottom = o, bounds.MaxY
+		}
+	}
+
+	return found
+}
+
+// buildingAt is like `objectAt` but only considers buildings, so squads
+// marching past don't get in the way of targeting one.
+func (g *GameScene) buildingAt(x, y float64) *entities.Building {
+	var found *entities.Building
+	var foundBottom float64
+	for _, o := range g.index.QueryPoint(x, y) {
+		b, ok := o.(*entities.Building)
+		if !ok {
+			continue
+		}
+
+		bounds, _ := g.index.Bounds(o)
+		if found == nil || bounds.MaxY > foundBottom {
+			found, foundBottom = b, bounds.MaxY
+		}
+	}
+
+	return found
 }
 
 func (g *GameScene) processMouseClick(x, y float64) {
-	// Looping over the layer keys
-LOOP:
-	for _, z := range g.interactables.Objects {
-		// Looping over the layer keys' objects
-		for _, o := range z {
-			oX, oY := o.TransCoords()
-			if x >= oX && x <= oX+float64(o.Img().Bounds().Dx()) && y >= oY && y <= oY+float64(o.Img().Bounds().Dy()) {
-				fmt.Printf("CLICKED ON THIS OBJECT --> %+v\n", o)
-				break LOOP
-			}
+	if o := g.objectAt(x, y); o != nil {
+		fmt.Printf("CLICKED ON THIS OBJECT --> %+v\n", o)
+	}
+
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	b := g.buildingAt(x, y)
+	switch {
+	case b == nil:
+		// Pressing on open ground starts a selection box
+		g.boxing = true
+		g.boxX, g.boxY = x, y
+	case b.CapturedBy == g.player && shift:
+		g.toggleSelected(b)
+	case b.CapturedBy == g.player:
+		// Pressing on one of our buildings starts dragging a send order from
+		// it, along with the rest of the selection if it's part of it
+		if !slices.Contains(g.selected, b) {
+			g.selected = []*entities.Building{b}
 		}
+		g.dragSource = b
+	case len(g.selected) > 0:
+		// Clicking on someone else's building sends the whole selection
+		g.sendSelected(b)
+	}
+}
+
+// processMouseRelease completes a send order when the drag that started on
+// one of our buildings is released over another building, or selects our
+// buildings inside a selection box.
+func (g *GameScene) processMouseRelease(x, y float64) {
+	if g.boxing {
+		g.boxing = false
+		g.selectInBox(g.boxX, g.boxY, x, y, ebiten.IsKeyPressed(ebiten.KeyShift))
+		return
+	}
+
+	source := g.dragSource
+	g.dragSource = nil
+	if source == nil {
+		return
+	}
+
+	target := g.buildingAt(x, y)
+	if target == nil || target == source {
+		return
+	}
+
+	g.sendSelected(target)
+}
+
+// processUpgradeKey starts upgrading the building under the cursor.
+func (g *GameScene) processUpgradeKey() {
+	b := g.buildingAt(g.cursorWorld())
+	if b == nil {
+		return
+	}
+
+	g.sim.Enqueue(sim.Command{
+		Player: g.player,
+		Target: b.Id,
+		Type:   constants.UPGRADE_COMMAND,
+	})
+}
+
+// processUnitKey switches the building under the cursor to producing the
+// next unit type.
+func (g *GameScene) processUnitKey() {
+	b := g.buildingAt(g.cursorWorld())
+	if b == nil {
+		return
+	}
+
+	next := constants.UnitTypes[0]
+	if i := slices.Index(constants.UnitTypes, b.Unit); i >= 0 {
+		next = constants.UnitTypes[(i+1)%len(constants.UnitTypes)]
 	}
+
+	g.sim.Enqueue(sim.Command{
+		Player: g.player,
+		Target: b.Id,
+		Type:   constants.UNIT_COMMAND,
+		Unit:   next,
+	})
 }
 
 // TODO: Think about eliminating `FirstLoad` and putting that logic here
-func NewGameScene() *GameScene {
+func NewGameScene(setup *MatchSetup, result *MatchResult) *GameScene {
 	return &GameScene{
 		Cursor: services.NewCursorService("./assets/ui/cursor_0.png"),
+		player: constants.BLUE,
+		result: result,
+		setup:  setup,
+		speed:  1,
 	}
 }
 
+// entityBounds returns the world-space rect covered by the entity's image.
+func entityBounds(o entities.IEntity) spatial.Rect {
+	tx, ty := o.TransCoords()
+	bounds := o.Img().Bounds()
+
+	return spatial.NewRect(tx, ty, float64(bounds.Dx()), float64(bounds.Dy()))
+}
+
 func assignObject(obj assets.TileMapObjectsJson, tileset assets.Tileset) (entities.IEntity, error) {
 	coercedType := constants.LayerRenderableType(obj.Type)
 
@@ -252,17 +683,15 @@ func assignObject(obj assets.TileMapObjectsJson, tileset assets.Tileset) (entiti
 		return assignCliff(obj, tileset)
 	case constants.STAIRS:
 		return assignStairs(obj, tileset)
+	case constants.TRIGGER:
+		return assignTrigger(obj)
 	}
 
 	return nil, fmt.Errorf("Unsupported object type: (%v)", obj.Type)
 }
 
 func assignBuilding(obj assets.TileMapObjectsJson, tileset assets.Tileset) (*entities.Building, error) {
-	objProps := make(map[string]any)
-
-	for _, p := range obj.Properties {
-		objProps[p.Name] = p.Value
-	}
+	objProps := objectProps(obj)
 
 	capacity, err := utils.SafeConvertUint8(objProps["capacity"])
 	if err != nil {
@@ -273,11 +702,20 @@ func assignBuilding(obj assets.TileMapObjectsJson, tileset assets.Tileset) (*ent
 
 	isSpawn := utils.SafeConvertBool(objProps["is_spawn"])
 
+	neutralRegen, err := utils.SafeConvertFloat64(objProps["neutral_regen"])
+	if err != nil {
+		return nil, err
+	}
+
 	occ, err := utils.SafeConvertUint8(objProps["occupancy"])
 	if err != nil {
 		return nil, err
 	}
 
+	rebel := utils.SafeConvertBool(objProps["rebel"])
+
+	unit := utils.SafeConvertString(objProps["unit"])
+
 	img := tileset.Img(obj.Gid)
 
 	tx, ty := obj.X, obj.Y
@@ -305,10 +743,13 @@ func assignBuilding(obj assets.TileMapObjectsJson, tileset assets.Tileset) (*ent
 			Tx: tx,
 			Ty: ty,
 		},
-		Capacity:   capacity,
-		CapturedBy: constants.Player(capBy),
-		IsSpawn:    isSpawn,
-		Occupancy:  occ,
+		Capacity:     capacity,
+		CapturedBy:   constants.Player(capBy),
+		IsSpawn:      isSpawn,
+		NeutralRegen: neutralRegen,
+		Occupancy:    occ,
+		Rebel:        rebel,
+		Unit:         constants.UnitType(unit),
 	}, nil
 }
 
@@ -340,11 +781,7 @@ func assignCliff(obj assets.TileMapObjectsJson, tileset assets.Tileset) (*entiti
 }
 
 func assignStairs(obj assets.TileMapObjectsJson, tileset assets.Tileset) (*entities.Stairs, error) {
-	objProps := make(map[string]any)
-
-	for _, p := range obj.Properties {
-		objProps[p.Name] = p.Value
-	}
+	objProps := objectProps(obj)
 
 	ascend := utils.SafeConvertString(objProps["ascend"])
 	if ascend == "" {
@@ -384,44 +821,107 @@ func assignStairs(obj assets.TileMapObjectsJson, tileset assets.Tileset) (*entit
 	}, nil
 }
 
+// assignTrigger reads a trigger's conditions and actions from its properties.
+// Triggers are rectangles in Tiled rather than tiles, so they have no image.
+func assignTrigger(obj assets.TileMapObjectsJson) (*entities.Trigger, error) {
+	objProps := objectProps(obj)
+
+	numbers := make(map[string]float64)
+	for _, name := range []string{"building", "spawn_target", "tick", "troops_below"} {
+		n, err := utils.SafeConvertFloat64(objProps[name])
+		if err != nil {
+			return nil, fmt.Errorf("Trigger (%d) has an invalid `%s`: %w", obj.Id, name, err)
+		}
+		numbers[name] = n
+	}
+
+	spawnTroops, err := utils.SafeConvertUint8(objProps["spawn_troops"])
+	if err != nil {
+		return nil, err
+	}
+
+	trigger := &entities.Trigger{
+		Coordinates: components.Coordinates{
+			X: obj.X,
+			Y: obj.Y,
+		},
+		Dimensions: components.Dimensions{
+			Height: obj.Height,
+			Width:  obj.Width,
+		},
+		LayerObject: components.LayerObject{
+			Class: constants.LayerRenderableType(obj.Type),
+			Id:    obj.Id,
+			Name:  constants.LayerObjectName(obj.Name),
+		},
+		Transformable: components.Transformable{
+			Tx: obj.X,
+			Ty: obj.Y,
+		},
+		Building:    constants.ID(numbers["building"]),
+		CapturedBy:  constants.Player(utils.SafeConvertString(objProps["captured_by"])),
+		EndMatch:    constants.Player(utils.SafeConvertString(objProps["end_match"])),
+		EnteredBy:   constants.Player(utils.SafeConvertString(objProps["entered_by"])),
+		Message:     utils.SafeConvertString(objProps["message"]),
+		Reveal:      constants.Player(utils.SafeConvertString(objProps["reveal"])),
+		SetOwner:    constants.Player(utils.SafeConvertString(objProps["set_owner"])),
+		SpawnOwner:  constants.Player(utils.SafeConvertString(objProps["spawn_owner"])),
+		SpawnTarget: constants.ID(numbers["spawn_target"]),
+		SpawnTroops: spawnTroops,
+		SpawnUnit:   constants.UnitType(utils.SafeConvertString(objProps["spawn_unit"])),
+		Tick:        uint64(numbers["tick"]),
+		TroopsBelow: int(numbers["troops_below"]),
+		TroopsOf:    constants.Player(utils.SafeConvertString(objProps["troops_of"])),
+	}
+
+	hasArea := trigger.Width > 0 && trigger.Height > 0
+	switch {
+	case trigger.Building == 0 && (trigger.CapturedBy != "" || trigger.SetOwner != ""):
+		return nil, fmt.Errorf("Trigger (%d) needs a `building` for `captured_by` and `set_owner`", obj.Id)
+	case !hasArea && trigger.EnteredBy != "":
+		return nil, fmt.Errorf("Trigger (%d) needs an area for `entered_by`", obj.Id)
+	case trigger.SpawnTroops > 0 && (trigger.SpawnOwner == "" || trigger.SpawnTarget == 0):
+		return nil, fmt.Errorf("Trigger (%d) needs a `spawn_owner` and `spawn_target` for `spawn_troops`", obj.Id)
+	case trigger.SpawnTroops > 0 && !hasArea && trigger.Building == 0:
+		return nil, fmt.Errorf("Trigger (%d) needs an area or a `building` to spawn troops from", obj.Id)
+	}
+
+	return trigger, nil
+}
+
 func renderBuildingBanner(o entities.IEntity, screen *ebiten.Image, opts *ebiten.DrawImageOptions) {
 	// Check if building has occupancy & capacity, then display banner "O/C"
 	if o.Type() == constants.BUILDING {
 		coObj := o.(*entities.Building)
-		if coObj.IsSpawn {
+		if coObj.Capacity > 0 {
 			tx, ty := o.TransCoords()
+			centerX := tx + float64(o.Img().Bounds().Dx())/2
 			scaleAmount := 0.80
 			opts.GeoM.Scale(scaleAmount, scaleAmount)
-			opts.GeoM.Translate(tx+float64(o.Img().Bounds().Dx())/2, ty)
-			switch coObj.CapturedBy {
-			case constants.BLUE:
-				capBanner, _, err := ebitenutil.NewImageFromFile("./assets/ui/ribbon_blue.png")
-				if err != nil {
-					fmt.Printf("Unable to parse image: %v", err)
-				}
-				opts.GeoM.Translate(-float64(capBanner.Bounds().Dx())*scaleAmount/2, 0.0)
-				screen.DrawImage(capBanner, opts)
-			case constants.RED:
-				capBanner, _, err := ebitenutil.NewImageFromFile("./assets/ui/ribbon_red.png")
-				if err != nil {
-					fmt.Printf("Unable to parse image: %v", err)
-				}
-				opts.GeoM.Translate(-float64(capBanner.Bounds().Dx())*scaleAmount/2, 0.0)
-				screen.DrawImage(capBanner, opts)
-			default:
-				capBanner, _, err := ebitenutil.NewImageFromFile("./assets/ui/ribbon_gray.png")
-				if err != nil {
-					fmt.Printf("Unable to parse image: %v", err)
-				}
-				opts.GeoM.Translate(-float64(capBanner.Bounds().Dx())*scaleAmount/2, 0.0)
-				screen.DrawImage(capBanner, opts)
+			opts.GeoM.Translate(centerX, ty)
+
+			capBanner, ok := bannerImgs[coObj.CapturedBy]
+			if !ok {
+				capBanner = bannerImgs[constants.NONE]
 			}
+			bannerW := float64(capBanner.Bounds().Dx()) * scaleAmount
+			bannerH := float64(capBanner.Bounds().Dy()) * scaleAmount
+			opts.GeoM.Translate(-bannerW/2, 0.0)
+			screen.DrawImage(capBanner, opts)
 
 			textW, textH := text.Measure(fmt.Sprintf("%d/%d", coObj.Occupancy, coObj.Capacity), fontFace, 0)
 			tOpts := &text.DrawOptions{}
-			tOpts.GeoM.Translate(tx+float64(o.Img().Bounds().Dx())/2-textW/2, ty+(textH/4))
+			tOpts.GeoM.Translate(centerX-textW/2, ty+(textH/4))
 			tOpts.ColorScale.Scale(0, 0, 0, 1)
 			text.Draw(screen, fmt.Sprintf("%d/%d", coObj.Occupancy, coObj.Capacity), fontFace, tOpts)
+
+			// Upgrade progress bar hangs just below the ribbon
+			if coObj.Upgrading {
+				barW, barH := bannerW*0.6, 6.0
+				barX, barY := centerX-barW/2, ty+bannerH
+				vector.DrawFilledRect(screen, float32(barX), float32(barY), float32(barW), float32(barH), color.RGBA{40, 40, 40, 200}, false)
+				vector.DrawFilledRect(screen, float32(barX), float32(barY), float32(barW*coObj.UpgradeProgress), float32(barH), color.RGBA{240, 200, 60, 255}, false)
+			}
 		}
 		opts.GeoM.Reset()
 	}
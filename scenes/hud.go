@@ -0,0 +1,29 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var hudBackground = color.RGBA{20, 20, 30, 200}
+
+// drawHUD shows the local player's resources and abilities in the top-left
+// corner of the screen.
+func (g *GameScene) drawHUD(screen *ebiten.Image) {
+	economy := g.sim.Economy
+	label := fmt.Sprintf("Gold %d (+%.1f/s)", economy.Balance(g.player), economy.Income(g.player, g.sim.Buildings))
+
+	textW, textH := text.Measure(label, fontFace, 0)
+	vector.DrawFilledRect(screen, 8, 8, float32(textW+16), float32(textH+16), hudBackground, false)
+
+	tOpts := &text.DrawOptions{}
+	tOpts.GeoM.Translate(16, 16)
+	tOpts.ColorScale.ScaleWithColor(color.RGBA{255, 215, 0, 255})
+	text.Draw(screen, label, fontFace, tOpts)
+
+	g.drawAbilities(screen, textH+32)
+}
@@ -0,0 +1,61 @@
+package scenes
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	maxMessages     = 3
+	messageDuration = 6.0 // Seconds a message stays up, in real time
+)
+
+// message is a line of text for the player, e.g. a trigger's or who was just
+// eliminated.
+type message struct {
+	remaining float64 // Seconds left on screen
+	text      string
+}
+
+// showMessage puts the text up at the top of the screen, pushing out the
+// oldest message once there are too many.
+func (g *GameScene) showMessage(text string) {
+	g.messages = append(g.messages, message{
+		remaining: messageDuration,
+		text:      text,
+	})
+	if len(g.messages) > maxMessages {
+		g.messages = g.messages[len(g.messages)-maxMessages:]
+	}
+}
+
+// updateMessages takes down messages that have been up long enough.
+func (g *GameScene) updateMessages(dt float64) {
+	shown := g.messages[:0]
+	for _, m := range g.messages {
+		if m.remaining -= dt; m.remaining > 0 {
+			shown = append(shown, m)
+		}
+	}
+	g.messages = shown
+}
+
+// drawMessages shows the messages centered along the top of the screen,
+// oldest first.
+func (g *GameScene) drawMessages(screen *ebiten.Image) {
+	y := 16.0
+	for _, m := range g.messages {
+		textW, textH := text.Measure(m.text, fontFace, 0)
+		x := (float64(screen.Bounds().Dx()) - textW) / 2
+		vector.DrawFilledRect(screen, float32(x-8), float32(y-4), float32(textW+16), float32(textH+8), hudBackground, false)
+
+		tOpts := &text.DrawOptions{}
+		tOpts.GeoM.Translate(x, y)
+		tOpts.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, m.text, fontFace, tOpts)
+		y += textH + 12
+	}
+}
@@ -0,0 +1,143 @@
+package scenes
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/assets"
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/utils"
+)
+
+var blockedSides = map[string]navigation.Side{
+	"blockedNorth": navigation.NorthSide,
+	"blockedEast":  navigation.EastSide,
+	"blockedSouth": navigation.SouthSide,
+	"blockedWest":  navigation.WestSide,
+}
+
+// buildNavGrid combines the elevation tile layers, the `blocked*` edge flags
+// carried by cliffs, stairs and plateau objects, and the building footprints
+// into a single navigation grid.
+func (g *GameScene) buildNavGrid() *navigation.Grid {
+	grid := navigation.NewGrid(g.tileMapJson.Width, g.tileMapJson.Height, constants.Tilesize)
+
+	for _, layer := range g.tileMapJson.Layers {
+		// Elevation tile layers are named `elevation_<level>`, any tile on
+		// one raises its cell to that level.
+		if level, ok := elevationLevel(layer.Name); ok {
+			for idx, tileId := range layer.Data {
+				if tileId == 0 {
+					continue
+				}
+
+				x, y := idx%layer.Width, idx/layer.Width
+				if c := grid.Cell(x, y); c != nil && c.Elevation < level {
+					grid.SetElevation(x, y, level)
+				}
+			}
+		}
+
+		for _, obj := range layer.Objects {
+			props := objectProps(obj)
+			// Tile objects are anchored on their bottom-left corner
+			x, y := grid.CellAt(obj.X, obj.Y-float64(obj.Height))
+
+			if constants.LayerRenderableType(obj.Type) == constants.STAIRS {
+				grid.SetStairs(
+					x,
+					y,
+					constants.CardinalDirection(utils.SafeConvertString(props["ascend"])),
+					constants.CardinalDirection(utils.SafeConvertString(props["descend"])),
+				)
+			}
+
+			if !utils.SafeConvertBool(props["blocked"]) {
+				continue
+			}
+
+			var sides navigation.Side
+			for name, side := range blockedSides {
+				if utils.SafeConvertBool(props[name]) {
+					sides |= side
+				}
+			}
+
+			// Closed on every side means nothing can ever stand there
+			if sides == navigation.NorthSide|navigation.EastSide|navigation.SouthSide|navigation.WestSide {
+				grid.SetSolid(x, y)
+			}
+			grid.BlockEdges(x, y, sides)
+		}
+	}
+
+	for _, b := range g.buildings {
+		grid.AddFootprint(b.Id, g.buildingFootprint(b))
+	}
+
+	return grid
+}
+
+// buildingFootprint returns the world-space polygon a building stands on: the
+// collision shape drawn on its tile when there is one, otherwise the bottom
+// half of its sprite (the top half being roof that units walk behind).
+func (g *GameScene) buildingFootprint(b *entities.Building) []navigation.Point {
+	tx, ty := b.TransCoords()
+
+	if dt, ok := g.tilesetFor(b.Gid).(*assets.DynamicTileset); ok {
+		if collision := dt.Collision(b.Gid); collision != nil {
+			polygon := make([]navigation.Point, 0, len(collision))
+			for _, p := range collision {
+				polygon = append(polygon, navigation.Point{X: tx + p.X, Y: ty + p.Y})
+			}
+
+			return polygon
+		}
+	}
+
+	w, h := float64(b.Width), float64(b.Height)
+	return []navigation.Point{
+		{X: tx, Y: ty + h/2},
+		{X: tx + w, Y: ty + h/2},
+		{X: tx + w, Y: ty + h},
+		{X: tx, Y: ty + h},
+	}
+}
+
+// tilesetFor returns the tileset that owns the given global ID, i.e. the one
+// with the highest `firstgid` not above it.
+func (g *GameScene) tilesetFor(gid constants.ID) assets.Tileset {
+	for i := len(g.tilesets) - 1; i >= 0; i-- {
+		if gid >= g.tilesets[i].Gid() {
+			return g.tilesets[i]
+		}
+	}
+
+	return nil
+}
+
+func elevationLevel(layerName string) (uint8, bool) {
+	suffix, ok := strings.CutPrefix(layerName, "elevation_")
+	if !ok {
+		return 0, false
+	}
+
+	level, err := strconv.ParseUint(suffix, 10, 8)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint8(level), true
+}
+
+func objectProps(obj assets.TileMapObjectsJson) map[string]any {
+	objProps := make(map[string]any)
+
+	for _, p := range obj.Properties {
+		objProps[p.Name] = p.Value
+	}
+
+	return objProps
+}
@@ -0,0 +1,70 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// drawObjectives lists the campaign objectives under the game speed in the
+// top-right corner of the screen, ticking off the ones that are done.
+func (g *GameScene) drawObjectives(screen *ebiten.Image) {
+	objectives := g.sim.Objectives
+	if len(objectives.Objectives) == 0 {
+		return
+	}
+
+	y := 16 + fontFace.Size*2
+	right := float64(screen.Bounds().Dx()) - 16
+	for i, o := range objectives.Objectives {
+		mark, clr := "[ ]", color.Color(color.White)
+		if objectives.Done(i, g.sim.Buildings) {
+			mark, clr = "[x]", color.RGBA{120, 220, 120, 255}
+		}
+
+		label := mark + " " + objectiveLabel(o)
+		if o.Type == constants.SURVIVE_OBJECTIVE && !objectives.Done(i, g.sim.Buildings) {
+			left := o.Seconds - float64(g.sim.Tick)*sim.TickDuration
+			label += " " + formatSeconds(left)
+		}
+
+		textW, textH := text.Measure(label, fontFace, 0)
+		vector.DrawFilledRect(screen, float32(right-textW-8), float32(y-4), float32(textW+16), float32(textH+8), hudBackground, false)
+
+		tOpts := &text.DrawOptions{}
+		tOpts.GeoM.Translate(right-textW, y)
+		tOpts.ColorScale.ScaleWithColor(clr)
+		text.Draw(screen, label, fontFace, tOpts)
+		y += textH + 12
+	}
+}
+
+// objectiveLabel describes the objective, preferring the mission's own words.
+func objectiveLabel(o sim.Objective) string {
+	if o.Description != "" {
+		return o.Description
+	}
+
+	switch o.Type {
+	case constants.CAPTURE_OBJECTIVE:
+		return fmt.Sprintf("Capture building %d", o.Target)
+	case constants.SURVIVE_OBJECTIVE:
+		return "Survive " + formatSeconds(o.Seconds)
+	case constants.WIN_OBJECTIVE:
+		return fmt.Sprintf("Win holding %d buildings", o.Buildings)
+	}
+
+	return string(o.Type)
+}
+
+// formatSeconds formats a duration as minutes and seconds, e.g. 3:05.
+func formatSeconds(seconds float64) string {
+	s := int(math.Ceil(math.Max(seconds, 0)))
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,136 @@
+package scenes
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/assets"
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// MatchResult is how the last match played out. The game scene fills it in
+// when the match ends and the results scene shows it.
+type MatchResult struct {
+	Eliminated []constants.Player
+	// Mission is the ID of the campaign mission played, if any.
+	Mission         string
+	MissionComplete bool
+	Player          constants.Player // The local player
+	Winners         []constants.Player
+}
+
+type ResultsScene struct {
+	loaded    bool
+	result    *MatchResult
+	titleFace *text.GoTextFace
+}
+
+func (r *ResultsScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{30, 30, 40, 255})
+	w, h := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+
+	title := "Defeat"
+	switch {
+	case len(r.result.Winners) == 0:
+		title = "Draw"
+	case slices.Contains(r.result.Winners, r.result.Player):
+		title = "Victory"
+	}
+	drawCentered(screen, title, r.titleFace, w/2, h/3, color.White)
+
+	lines := []string{
+		fmt.Sprintf("Winners: %s", joinPlayers(r.result.Winners)),
+		fmt.Sprintf("Eliminated: %s", joinPlayers(r.result.Eliminated)),
+		"",
+		"Press Enter to play again, M for the menu or Esc to quit",
+	}
+	if r.result.Mission != "" {
+		mission := "Mission failed"
+		if r.result.MissionComplete {
+			mission = "Mission complete"
+		}
+		lines = append([]string{mission, ""}, lines...)
+	}
+	for i, line := range lines {
+		drawCentered(screen, line, fontFace, w/2, h/2+float64(i)*fontFace.Size*1.5, color.White)
+	}
+}
+
+func (r *ResultsScene) FirstLoad() {
+	if fontSource == nil {
+		s, err := text.NewGoTextFaceSource(bytes.NewReader(assets.DepartMono_otf))
+		if err != nil {
+			fmt.Printf("Unable to generate font source: %v", err)
+		}
+		fontSource = s
+		fontFace = &text.GoTextFace{
+			Source: fontSource,
+			Size:   16,
+		}
+	}
+
+	r.titleFace = &text.GoTextFace{
+		Source: fontSource,
+		Size:   64,
+	}
+	r.loaded = true
+}
+
+func (r *ResultsScene) IsLoaded() bool {
+	return r.loaded
+}
+
+func (r *ResultsScene) OnEnter() {
+	ebiten.SetCursorMode(ebiten.CursorModeVisible)
+}
+
+func (r *ResultsScene) OnExit() {}
+
+func (r *ResultsScene) Update() SceneId {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return GameSceneId
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		return StartSceneId
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return ExitSceneId
+	}
+
+	return ResultsSceneId
+}
+
+func NewResultsScene(result *MatchResult) *ResultsScene {
+	return &ResultsScene{
+		result: result,
+	}
+}
+
+func drawCentered(screen *ebiten.Image, s string, face *text.GoTextFace, x, y float64, clr color.Color) {
+	textW, textH := text.Measure(s, face, 0)
+	tOpts := &text.DrawOptions{}
+	tOpts.GeoM.Translate(x-textW/2, y-textH/2)
+	tOpts.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, face, tOpts)
+}
+
+func joinPlayers(players []constants.Player) string {
+	if len(players) == 0 {
+		return "-"
+	}
+
+	names := make([]string, 0, len(players))
+	for _, p := range players {
+		names = append(names, string(p))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+var _ Scene = (*ResultsScene)(nil)
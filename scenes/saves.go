@@ -0,0 +1,210 @@
+package scenes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ehutchllew/autoarmy/assets"
+	"github.com/ehutchllew/autoarmy/campaign"
+	"github.com/ehutchllew/autoarmy/saves"
+	"github.com/ehutchllew/autoarmy/sim"
+)
+
+// matchSave is the name of the file the match left for the menu is saved to.
+const matchSave = "match.json"
+
+// savePath is where the game keeps the file with the given name, e.g. the
+// replay F10 plays back.
+func savePath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "autoarmy", name), nil
+}
+
+// saveMatch stores the match in progress, with the rules it's played under,
+// so it can be resumed from the menu.
+func (g *GameScene) saveMatch() {
+	if g.replaying || g.sim.Match.Ended {
+		return
+	}
+
+	fp, err := savePath(matchSave)
+	if err != nil {
+		fmt.Printf("Unable to find save directory: %v\n", err)
+		return
+	}
+
+	save := &saves.Save{
+		Handicaps:  g.sim.Handicaps,
+		Map:        g.mapPath,
+		Mission:    g.mission,
+		Objectives: g.sim.Objectives.Objectives,
+		Player:     g.player,
+		Rules:      *g.sim.Rules.Clone(),
+		Seed:       g.sim.Seed,
+		State:      g.sim.State(),
+		Teams:      g.sim.Teams,
+	}
+	if err := saves.Write(fp, save); err != nil {
+		fmt.Printf("Unable to save match: %v\n", err)
+		return
+	}
+	fmt.Printf("Match saved to %s\n", fp)
+}
+
+// resumeMatch plays on from the save, under the rules stored in it rather
+// than the current rules file. The save is used up, leaving the match again
+// writes a new one.
+func (g *GameScene) resumeMatch(save *saves.Save) {
+	g.mapPath = save.Map
+	g.mission = save.Mission
+	g.startMatch(g.loadMap(save.Map), sim.Setup{
+		Handicaps:  save.Handicaps,
+		Objectives: save.Objectives,
+		Player:     save.Player,
+		Rules:      &save.Rules,
+		Seed:       save.Seed,
+		Teams:      save.Teams,
+	})
+	if err := g.sim.Restore(save.State); err != nil {
+		log.Fatalf("Unable to restore saved match: %v", err)
+	}
+	g.sync()
+
+	fp, err := savePath(matchSave)
+	if err != nil {
+		fmt.Printf("Unable to find save directory: %v\n", err)
+		return
+	}
+	if err := os.Remove(fp); err != nil {
+		fmt.Printf("Unable to remove saved match: %v\n", err)
+	}
+}
+
+// readMatchSave returns the match left unfinished, or nil if there is none.
+func readMatchSave() *saves.Save {
+	fp, err := savePath(matchSave)
+	if err != nil {
+		fmt.Printf("Unable to find save directory: %v\n", err)
+		return nil
+	}
+
+	save, err := saves.Read(fp)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		fmt.Printf("Unable to load saved match: %v\n", err)
+		return nil
+	}
+
+	return save
+}
+
+// writeReplay stores every command of the match that just ended, so F10 can
+// play it back.
+func (g *GameScene) writeReplay() {
+	if g.replaying {
+		return
+	}
+
+	fp, err := savePath("lastmatch.replay.json")
+	if err != nil {
+		fmt.Printf("Unable to find save directory: %v\n", err)
+		return
+	}
+
+	replay := &saves.Replay{
+		Commands:   g.sim.History,
+		Handicaps:  g.sim.Handicaps,
+		Map:        g.mapPath,
+		Mission:    g.mission,
+		Objectives: g.sim.Objectives.Objectives,
+		Player:     g.player,
+		Rules:      *g.sim.Rules.Clone(),
+		Seed:       g.sim.Seed,
+		Teams:      g.sim.Teams,
+	}
+	if err := saves.WriteReplay(fp, replay); err != nil {
+		fmt.Printf("Unable to save replay: %v\n", err)
+		return
+	}
+	fmt.Printf("Replay saved to %s\n", fp)
+}
+
+// playReplay restarts the last finished match and feeds it the same commands
+// on the same ticks.
+func (g *GameScene) playReplay() {
+	fp, err := savePath("lastmatch.replay.json")
+	if err != nil {
+		fmt.Printf("Unable to find save directory: %v\n", err)
+		return
+	}
+
+	replay, err := saves.ReadReplay(fp)
+	if err != nil {
+		fmt.Printf("Unable to load replay: %v\n", err)
+		return
+	}
+
+	g.mapPath = replay.Map
+	g.mission = replay.Mission
+	g.startMatch(g.loadMap(replay.Map), sim.Setup{
+		Handicaps:  replay.Handicaps,
+		Objectives: replay.Objectives,
+		Player:     replay.Player,
+		Rules:      &replay.Rules,
+		Seed:       replay.Seed,
+		Teams:      replay.Teams,
+	})
+	g.replay = replay.Commands
+	g.replaying = true
+	fmt.Printf("Playing replay from %s\n", fp)
+}
+
+// recordMission marks the campaign mission just won as completed, unlocking
+// the missions it leads to.
+func (g *GameScene) recordMission() {
+	if g.replaying || g.mission == "" || !g.result.MissionComplete {
+		return
+	}
+
+	c, err := campaign.Load(campaignPath)
+	if err != nil {
+		fmt.Printf("Unable to load campaign: %v\n", err)
+		return
+	}
+	fp, err := savePath("campaign.json")
+	if err != nil {
+		fmt.Printf("Unable to find save directory: %v\n", err)
+		return
+	}
+	progress, err := campaign.ReadProgress(fp)
+	if err != nil {
+		fmt.Printf("Unable to load campaign progress: %v\n", err)
+		return
+	}
+
+	c.Complete(progress, g.mission)
+	if err := campaign.WriteProgress(fp, progress); err != nil {
+		fmt.Printf("Unable to save campaign progress: %v\n", err)
+		return
+	}
+	fmt.Printf("Mission %s completed\n", g.mission)
+}
+
+func (g *GameScene) loadMap(fp string) *assets.TileMapJson {
+	tileMapJson, err := assets.NewTileMapJson(fp)
+	if err != nil {
+		log.Fatalf("Unable to load Tilemap JSON: %v", err)
+	}
+
+	return tileMapJson
+}
@@ -12,6 +12,7 @@ const (
 	PauseSceneId
 	StartSceneId
 	ExitSceneId
+	ResultsSceneId
 )
 
 type Scene interface {
@@ -0,0 +1,104 @@
+package scenes
+
+import (
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/ehutchllew/autoarmy/sim"
+	"github.com/ehutchllew/autoarmy/spatial"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Boxes smaller than this (in pixels) are treated as a click on open ground
+const minBoxSize = 8
+
+func (g *GameScene) deselect(b *entities.Building) {
+	g.selected = slices.DeleteFunc(g.selected, func(s *entities.Building) bool {
+		return s == b
+	})
+}
+
+// drawDragLine shows the send order currently being dragged, from every
+// building it will go out from.
+func (g *GameScene) drawDragLine(screen *ebiten.Image) {
+	if g.dragSource == nil {
+		return
+	}
+
+	cX, cY := g.cursorWorld()
+	for _, b := range g.selected {
+		sX, sY := g.sim.Building(b.Id).Entrance()
+		vector.StrokeLine(screen, float32(sX), float32(sY), float32(cX), float32(cY), 3, color.RGBA{255, 255, 255, 180}, true)
+	}
+}
+
+func (g *GameScene) drawSelection(screen *ebiten.Image) {
+	for _, b := range g.selected {
+		r := entityBounds(b)
+		vector.StrokeRect(screen, float32(r.MinX), float32(r.MinY), float32(r.MaxX-r.MinX), float32(r.MaxY-r.MinY), 2, color.RGBA{255, 255, 255, 200}, false)
+	}
+
+	if g.boxing {
+		cX, cY := g.cursorWorld()
+		r := boxRect(g.boxX, g.boxY, cX, cY)
+		vector.DrawFilledRect(screen, float32(r.MinX), float32(r.MinY), float32(r.MaxX-r.MinX), float32(r.MaxY-r.MinY), color.RGBA{255, 255, 255, 40}, false)
+		vector.StrokeRect(screen, float32(r.MinX), float32(r.MinY), float32(r.MaxX-r.MinX), float32(r.MaxY-r.MinY), 1, color.RGBA{255, 255, 255, 200}, false)
+	}
+}
+
+// selectInBox selects every one of our buildings touched by the box. Unless
+// `additive`, it replaces the current selection.
+func (g *GameScene) selectInBox(x0, y0, x1, y1 float64, additive bool) {
+	if !additive {
+		g.selected = g.selected[:0]
+	}
+
+	r := boxRect(x0, y0, x1, y1)
+	if r.MaxX-r.MinX < minBoxSize && r.MaxY-r.MinY < minBoxSize {
+		return
+	}
+
+	for _, o := range g.index.QueryRect(r) {
+		b, ok := o.(*entities.Building)
+		if ok && b.CapturedBy == g.player && !slices.Contains(g.selected, b) {
+			g.selected = append(g.selected, b)
+		}
+	}
+}
+
+// sendSelected issues one send order from every selected building.
+func (g *GameScene) sendSelected(target *entities.Building) {
+	sources := make([]constants.ID, 0, len(g.selected))
+	for _, b := range g.selected {
+		sources = append(sources, b.Id)
+	}
+
+	g.sim.Enqueue(sim.Command{
+		Player:  g.player,
+		Sources: sources,
+		Target:  target.Id,
+		Type:    constants.SEND_COMMAND,
+	})
+}
+
+func (g *GameScene) toggleSelected(b *entities.Building) {
+	if slices.Contains(g.selected, b) {
+		g.deselect(b)
+		return
+	}
+
+	g.selected = append(g.selected, b)
+}
+
+func boxRect(x0, y0, x1, y1 float64) spatial.Rect {
+	return spatial.Rect{
+		MinX: math.Min(x0, x1),
+		MinY: math.Min(y0, y1),
+		MaxX: math.Max(x0, x1),
+		MaxY: math.Max(y0, y1),
+	}
+}
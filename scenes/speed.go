@@ -0,0 +1,47 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// Game speeds the player can pick, as multiples of real time
+var speeds = []float64{0.5, 1, 2, 4}
+
+// processSpeedKeys pauses with Space and steps the game speed down and up
+// with - and =.
+func (g *GameScene) processSpeedKeys() {
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.paused = !g.paused
+	}
+
+	i := slices.Index(speeds, g.speed)
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) && i > 0 {
+		g.speed = speeds[i-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) && i < len(speeds)-1 {
+		g.speed = speeds[i+1]
+	}
+}
+
+// drawSpeed shows the game speed in the top-right corner of the screen.
+func (g *GameScene) drawSpeed(screen *ebiten.Image) {
+	label := fmt.Sprintf("%gx", g.speed)
+	if g.paused {
+		label = "Paused"
+	}
+	if g.replaying {
+		label = "Replay " + label
+	}
+
+	textW, _ := text.Measure(label, fontFace, 0)
+	tOpts := &text.DrawOptions{}
+	tOpts.GeoM.Translate(float64(screen.Bounds().Dx())-textW-16, 16)
+	tOpts.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, label, fontFace, tOpts)
+}
@@ -0,0 +1,111 @@
+package scenes
+
+import (
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/assets"
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type buildingSpriteKey struct {
+	level  constants.BuildingLevel
+	player constants.Player
+}
+
+// buildingSprites maps a building's level and owner to the tile in the
+// buildings tileset that depicts it, based on the image names
+// (e.g. "tower_blue").
+type buildingSprites struct {
+	gids     map[buildingSpriteKey]constants.ID
+	keys     map[constants.ID]buildingSpriteKey
+	tilesets map[constants.ID]*assets.DynamicTileset
+}
+
+var spriteLevels = map[string]constants.BuildingLevel{
+	"house":  constants.HOUSE_LEVEL,
+	"tower":  constants.TOWER_LEVEL,
+	"castle": constants.CASTLE_LEVEL,
+}
+
+func newBuildingSprites(tilesets []assets.Tileset) *buildingSprites {
+	bs := &buildingSprites{
+		gids:     make(map[buildingSpriteKey]constants.ID),
+		keys:     make(map[constants.ID]buildingSpriteKey),
+		tilesets: make(map[constants.ID]*assets.DynamicTileset),
+	}
+
+	for _, t := range tilesets {
+		dt, ok := t.(*assets.DynamicTileset)
+		if !ok {
+			continue
+		}
+
+		for i := 0; i < dt.Len(); i++ {
+			gid := dt.Gid() + constants.ID(i)
+			archetype, color, found := strings.Cut(dt.Name(gid), "_")
+			if !found {
+				continue
+			}
+
+			level, ok := spriteLevels[archetype]
+			if !ok {
+				continue
+			}
+
+			player := constants.Player(strings.ToUpper(color))
+			if player == "GRAY" {
+				player = constants.NONE
+			}
+
+			key := buildingSpriteKey{level, player}
+			bs.gids[key] = gid
+			bs.keys[gid] = key
+			bs.tilesets[gid] = dt
+		}
+	}
+
+	return bs
+}
+
+// Level returns the level depicted by the given building tile.
+func (bs *buildingSprites) Level(gid constants.ID) constants.BuildingLevel {
+	return bs.keys[gid].level
+}
+
+// Lookup returns the tile for the given level and owner. Not every color has
+// every level drawn, so it falls back to any tile of the same level.
+func (bs *buildingSprites) Lookup(level constants.BuildingLevel, player constants.Player) (constants.ID, *ebiten.Image, bool) {
+	gid, ok := bs.gids[buildingSpriteKey{level, player}]
+	if !ok {
+		for key, g := range bs.gids {
+			if key.level == level && (!ok || g < gid) {
+				gid, ok = g, true
+			}
+		}
+	}
+	if !ok {
+		return 0, nil, false
+	}
+
+	return gid, bs.tilesets[gid].Img(gid), true
+}
+
+// Apply swaps the building's sprite to match its level and owner, keeping it
+// anchored on the same bottom-center point.
+func (bs *buildingSprites) Apply(b *entities.Building) {
+	gid, img, ok := bs.Lookup(b.Level, b.CapturedBy)
+	if !ok || gid == b.Gid {
+		return
+	}
+
+	centerX := b.X + float64(b.Width)/2
+	b.Gid = gid
+	b.Image = img
+	b.Width = img.Bounds().Dx()
+	b.Height = img.Bounds().Dy()
+	b.X = centerX - float64(b.Width)/2
+	b.Tx = b.X
+	b.Ty = b.Y - float64(b.Height)
+}
@@ -0,0 +1,106 @@
+package scenes
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"log"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/colorm"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	// Unit sprite sheets are laid out in 192x192 frames, the first being idle
+	unitFrameSize = 192
+	unitScale     = 0.5
+	// At most this many sprites are drawn per squad, the rest is the label
+	maxDrawnUnits = 5
+)
+
+var (
+	unitImgs map[constants.UnitType]*ebiten.Image
+	// Unit sprites are drawn blue, every other owner is a hue shift away
+	unitHues = map[constants.Player]float64{
+		constants.BLUE:   0,
+		constants.GREEN:  -95 * math.Pi / 180,
+		constants.RED:    145 * math.Pi / 180,
+		constants.YELLOW: -160 * math.Pi / 180,
+	}
+)
+
+func loadUnitImgs() map[constants.UnitType]*ebiten.Image {
+	imgs := make(map[constants.UnitType]*ebiten.Image)
+	for _, unit := range constants.UnitTypes {
+		// Unit types without art of their own yet borrow the knight's
+		path := fmt.Sprintf("./assets/units/%s_blue.png", strings.ToLower(string(unit)))
+		if _, err := os.Stat(path); err != nil {
+			path = "./assets/units/knight_blue.png"
+		}
+
+		sheet, _, err := ebitenutil.NewImageFromFile(path)
+		if err != nil {
+			log.Fatalf("Unable to parse image: %v", err)
+		}
+
+		// Pre-scale the idle frame so squads can draw it 1:1
+		frame := sheet.SubImage(image.Rect(0, 0, unitFrameSize, unitFrameSize)).(*ebiten.Image)
+		size := int(unitFrameSize * unitScale)
+		img := ebiten.NewImage(size, size)
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM.Scale(unitScale, unitScale)
+		img.DrawImage(frame, opts)
+
+		imgs[unit] = img
+	}
+
+	return imgs
+}
+
+// drawSquads draws every marching squad as a small formation of its units
+// with its troop count above, back to front.
+func (g *GameScene) drawSquads(screen *ebiten.Image) {
+	squads := slices.Collect(maps.Values(g.squads))
+	slices.SortFunc(squads, func(a, b *entities.Squad) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.Id, b.Id))
+	})
+
+	for _, s := range squads {
+		if s.Image == nil || !g.squadVisible(s) {
+			continue
+		}
+
+		var cm colorm.ColorM
+		if hue, ok := unitHues[s.Owner]; ok {
+			cm.ChangeHSV(hue, 1, 1)
+		} else {
+			cm.ChangeHSV(0, 0, 1)
+		}
+
+		tx, ty := s.TransCoords()
+		n := min(int(s.Troops), maxDrawnUnits)
+		spacing := float64(s.Image.Bounds().Dx()) / 4
+		for i := 0; i < n; i++ {
+			// Spread the units in a shallow V behind the squad's position
+			offset := float64(i) - float64(n-1)/2
+			opts := &colorm.DrawImageOptions{}
+			opts.GeoM.Translate(tx+offset*spacing, ty-math.Abs(offset)*spacing/2)
+			colorm.DrawImage(screen, s.Image, cm, opts)
+		}
+
+		label := fmt.Sprintf("%d %s", s.Troops, string(s.Unit)[:1])
+		textW, textH := text.Measure(label, fontFace, 0)
+		tOpts := &text.DrawOptions{}
+		tOpts.GeoM.Translate(s.X-textW/2, ty+float64(s.Image.Bounds().Dy())/4-textH)
+		text.Draw(screen, label, fontFace, tOpts)
+	}
+}
@@ -0,0 +1,275 @@
+package scenes
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/assets"
+	"github.com/ehutchllew/autoarmy/campaign"
+	"github.com/ehutchllew/autoarmy/saves"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	campaignPath = "./assets/campaign.json"
+	skirmishMap  = "./assets/maps/map1.json"
+)
+
+// MatchSetup is what the next match is played on. The start scene picks it
+// and the game scene plays it.
+type MatchSetup struct {
+	Map     string            // The map of a skirmish
+	Mission *campaign.Mission // The campaign mission, nil for a skirmish
+	// Save is the match left unfinished to resume, nil to start a new one.
+	Save *saves.Save
+}
+
+// StartScene is the main menu, listing the campaign's missions followed by a
+// skirmish on the default map and, if one was left unfinished, the saved
+// match.
+type StartScene struct {
+	campaign *campaign.Campaign
+	loaded   bool
+	progress *campaign.Progress
+	save     *saves.Save // The match left unfinished, if any
+	// selected is an index into the missions, one past them for the skirmish
+	// and two past them for the saved match.
+	selected  int
+	setup     *MatchSetup
+	titleFace *text.GoTextFace
+}
+
+func (s *StartScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.RGBA{30, 30, 40, 255})
+	w, h := float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy())
+	lineH := fontFace.Size * 1.5
+
+	drawCentered(screen, "Auto Army", s.titleFace, w/2, h/6, color.White)
+
+	listX, listY := w/8, h/3
+	for i, entry := range s.entries() {
+		clr := color.Color(color.White)
+		if i < len(s.missions()) && !s.campaign.Unlocked(s.progress, s.missions()[i].Id) {
+			clr = color.Gray{128}
+		}
+		if i == s.selected {
+			entry = "> " + entry
+		}
+
+		tOpts := &text.DrawOptions{}
+		tOpts.GeoM.Translate(listX, listY+float64(i)*lineH)
+		tOpts.ColorScale.ScaleWithColor(clr)
+		text.Draw(screen, entry, fontFace, tOpts)
+	}
+
+	panelX := w / 2
+	for i, line := range s.details(w/2 - w/8) {
+		tOpts := &text.DrawOptions{}
+		tOpts.GeoM.Translate(panelX, listY+float64(i)*lineH)
+		text.Draw(screen, line, fontFace, tOpts)
+	}
+
+	drawCentered(screen, "Up/Down to choose, Enter to play, Esc to quit", fontFace, w/2, h-h/8, color.White)
+}
+
+func (s *StartScene) FirstLoad() {
+	if fontSource == nil {
+		src, err := text.NewGoTextFaceSource(bytes.NewReader(assets.DepartMono_otf))
+		if err != nil {
+			fmt.Printf("Unable to generate font source: %v", err)
+		}
+		fontSource = src
+		fontFace = &text.GoTextFace{
+			Source: fontSource,
+			Size:   16,
+		}
+	}
+
+	s.titleFace = &text.GoTextFace{
+		Source: fontSource,
+		Size:   64,
+	}
+
+	// Without a campaign there's still the skirmish to play
+	c, err := campaign.Load(campaignPath)
+	if err != nil {
+		fmt.Printf("Unable to load campaign: %v\n", err)
+	}
+	s.campaign = c
+	s.loaded = true
+}
+
+func (s *StartScene) IsLoaded() bool {
+	return s.loaded
+}
+
+// OnEnter refreshes the progress and the saved match, which the match just
+// played may have moved on or left behind.
+func (s *StartScene) OnEnter() {
+	ebiten.SetCursorMode(ebiten.CursorModeVisible)
+
+	s.save = readMatchSave()
+	s.selected = min(s.selected, len(s.entries())-1)
+
+	s.progress = &campaign.Progress{}
+	fp, err := savePath("campaign.json")
+	if err != nil {
+		fmt.Printf("Unable to find save directory: %v\n", err)
+		return
+	}
+	progress, err := campaign.ReadProgress(fp)
+	if err != nil {
+		fmt.Printf("Unable to load campaign progress: %v\n", err)
+		return
+	}
+	s.progress = progress
+}
+
+func (s *StartScene) OnExit() {}
+
+func (s *StartScene) Update() SceneId {
+	entries := len(s.entries())
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		s.selected = (s.selected + entries - 1) % entries
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		s.selected = (s.selected + 1) % entries
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		return ExitSceneId
+	}
+	if !inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		return StartSceneId
+	}
+
+	missions := s.missions()
+	s.setup.Save = nil
+	switch {
+	case s.selected == len(missions):
+		s.setup.Map = skirmishMap
+		s.setup.Mission = nil
+		return GameSceneId
+	case s.selected > len(missions):
+		s.resume()
+		return GameSceneId
+	}
+
+	m := missions[s.selected]
+	if !s.campaign.Unlocked(s.progress, m.Id) {
+		return StartSceneId
+	}
+	s.setup.Map = m.Map
+	s.setup.Mission = &m
+
+	return GameSceneId
+}
+
+// resume sets up the saved match to be played on from where it was left.
+// Playing it again afterwards starts its map or mission over.
+func (s *StartScene) resume() {
+	s.setup.Map = s.save.Map
+	s.setup.Mission = nil
+	for _, m := range s.missions() {
+		if m.Id == s.save.Mission {
+			s.setup.Mission = &m
+		}
+	}
+	s.setup.Save = s.save
+	s.save = nil
+}
+
+// details describes the selected mission, wrapped to the given width.
+func (s *StartScene) details(width float64) []string {
+	missions := s.missions()
+	switch {
+	case s.selected == len(missions):
+		return []string{"Skirmish", "", "A free match on the default map."}
+	case s.selected > len(missions):
+		return []string{"Saved match", "", "Play on from where the last match was left."}
+	}
+
+	m := missions[s.selected]
+	lines := []string{m.Name, ""}
+	switch {
+	case !s.campaign.Unlocked(s.progress, m.Id):
+		return append(lines, "Complete earlier missions to unlock.")
+	case slices.Contains(s.progress.Completed, m.Id):
+		lines = append(lines, "Completed", "")
+	}
+
+	lines = append(lines, wrapText(m.Briefing, width)...)
+	lines = append(lines, "", "Objectives:")
+	for _, o := range m.Objectives {
+		lines = append(lines, "  "+objectiveLabel(o))
+	}
+
+	return lines
+}
+
+// entries are the menu's labels, the missions' then the skirmish's.
+func (s *StartScene) entries() []string {
+	entries := make([]string, 0)
+	for _, m := range s.missions() {
+		label := m.Name
+		switch {
+		case slices.Contains(s.progress.Completed, m.Id):
+			label += " [done]"
+		case !s.campaign.Unlocked(s.progress, m.Id):
+			label += " [locked]"
+		}
+		entries = append(entries, label)
+	}
+
+	entries = append(entries, "Skirmish")
+	if s.save != nil {
+		entries = append(entries, "Continue")
+	}
+
+	return entries
+}
+
+func (s *StartScene) missions() []campaign.Mission {
+	if s.campaign == nil {
+		return nil
+	}
+
+	return s.campaign.Missions
+}
+
+func NewStartScene(setup *MatchSetup) *StartScene {
+	return &StartScene{
+		progress: &campaign.Progress{},
+		setup:    setup,
+	}
+}
+
+// wrapText breaks the text into lines no wider than `width` when drawn,
+// between words.
+func wrapText(s string, width float64) []string {
+	lines := make([]string, 0)
+	var line string
+	for _, word := range strings.Fields(s) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+
+		if w, _ := text.Measure(next, fontFace, 0); w > width && line != "" {
+			lines = append(lines, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+var _ Scene = (*StartScene)(nil)
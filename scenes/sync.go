@@ -0,0 +1,69 @@
+package scenes
+
+import (
+	"github.com/ehutchllew/autoarmy/components"
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/ehutchllew/autoarmy/sim"
+)
+
+// sync brings the entities drawn up to date with the simulation. Squads are
+// placed between where they were on the last two ticks, by how far we are
+// into the next one, so they move smoothly whatever the tick rate.
+func (g *GameScene) sync() {
+	for _, b := range g.buildings {
+		state := g.sim.Building(b.Id)
+		if state == nil {
+			continue
+		}
+
+		resprite := b.Level != state.Level || b.CapturedBy != state.CapturedBy
+		b.Capacity = state.Capacity
+		b.CapturedBy = state.CapturedBy
+		b.Level = state.Level
+		b.Occupancy = state.Occupancy
+		b.Unit = state.Unit
+		b.UpgradeProgress = state.UpgradeProgress
+		b.Upgrading = state.Upgrading
+		if resprite {
+			g.sprites.Apply(b)
+			g.index.Update(b, entityBounds(b))
+		}
+	}
+
+	alpha := g.accumulator / sim.TickDuration
+	marching := make(map[constants.ID]bool)
+	for _, s := range g.sim.Dispatch.Squads {
+		marching[s.Id] = true
+
+		view, ok := g.squads[s.Id]
+		if !ok {
+			view = &entities.Squad{
+				Id: s.Id,
+				Renderable: components.Renderable{
+					Image: unitImgs[s.Unit],
+				},
+			}
+			g.squads[s.Id] = view
+		}
+
+		view.Owner = s.Owner
+		view.Troops = s.Troops
+		view.Unit = s.Unit
+		view.X = s.PrevX + (s.X-s.PrevX)*alpha
+		view.Y = s.PrevY + (s.Y-s.PrevY)*alpha
+		g.index.Update(view, entityBounds(view))
+	}
+
+	for id, view := range g.squads {
+		if !marching[id] {
+			g.index.Remove(view)
+			delete(g.squads, id)
+		}
+	}
+}
+
+// squadVisible reports whether the local player can see the squad.
+func (g *GameScene) squadVisible(s *entities.Squad) bool {
+	return g.sim.Teams.Allied(s.Owner, g.player) || g.sim.Vision.Fog.Visible(g.player, s.X, s.Y)
+}
@@ -0,0 +1,191 @@
+package scenes
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/entities"
+	"github.com/ehutchllew/autoarmy/sim"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const tooltipPadding = 8
+
+// drawTooltip describes the building under the cursor as the local player
+// knows it, including every modifier that would apply to attacking it with
+// the current selection.
+func (g *GameScene) drawTooltip(screen *ebiten.Image) {
+	if g.dragSource != nil || g.boxing {
+		return
+	}
+
+	cX, cY := g.Cursor.Position()
+	b := g.buildingAt(g.cursorWorld())
+	if b == nil {
+		return
+	}
+
+	lines := g.tooltipLines(b)
+	lineH := fontFace.Size * 1.25
+	var w float64
+	for _, line := range lines {
+		lineW, _ := text.Measure(line, fontFace, 0)
+		w = max(w, lineW)
+	}
+	w += tooltipPadding * 2
+	h := lineH*float64(len(lines)) + tooltipPadding*2
+
+	// Keep the box on screen, flipping it to the other side of the cursor
+	x, y := float64(cX)+24, float64(cY)+24
+	if x+w > float64(screen.Bounds().Dx()) {
+		x = float64(cX) - 24 - w
+	}
+	if y+h > float64(screen.Bounds().Dy()) {
+		y = float64(cY) - 24 - h
+	}
+
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), color.RGBA{20, 20, 30, 220}, false)
+	for i, line := range lines {
+		tOpts := &text.DrawOptions{}
+		tOpts.GeoM.Translate(x+tooltipPadding, y+tooltipPadding+float64(i)*lineH)
+		text.Draw(screen, line, fontFace, tOpts)
+	}
+}
+
+func (g *GameScene) tooltipLines(b *entities.Building) []string {
+	shown := g.displayed(b).(*entities.Building)
+
+	lines := []string{
+		fmt.Sprintf("%s (%s)", shown.Level, shown.CapturedBy),
+		fmt.Sprintf("Troops %d/%d %s", shown.Occupancy, shown.Capacity, shown.Unit),
+	}
+	if shown != b {
+		lines[1] += " (last seen)"
+	}
+	allied := shown.CapturedBy != g.player && shown.CapturedBy != constants.NONE && g.sim.Teams.Allied(shown.CapturedBy, g.player)
+	if allied {
+		lines[0] += " ally"
+	}
+
+	// Modifiers come from where the building stands, and its level as known
+	state := *g.sim.Building(b.Id)
+	state.Level = shown.Level
+	combat := g.sim.Combat
+
+	archetype, elevation := combat.DefenseModifiers(&state)
+	lines = append(lines, fmt.Sprintf("Defense x%.2f", archetype*elevation))
+	lines = append(lines, fmt.Sprintf("  %s x%.2f", shown.Level, archetype))
+	if elevation != 1 {
+		lines = append(lines, fmt.Sprintf("  High ground x%.2f", elevation))
+	}
+
+	if towerRange := combat.TowerRange(&state); towerRange > 0 {
+		line := fmt.Sprintf("Range %.1f tiles", towerRange)
+		if bonus := towerRange - combat.BaseTowerRange; bonus > 0 {
+			line += fmt.Sprintf(" (+%.1f plateau)", bonus)
+		}
+		lines = append(lines, line)
+	}
+
+	if shown.CapturedBy == constants.NONE && state.Rebel {
+		lines = append(lines, "Rebel, attacks nearby players")
+	}
+	if shown == b && state.Frozen > 0 {
+		lines = append(lines, fmt.Sprintf("Frozen %.0fs", math.Ceil(state.Frozen)))
+	}
+	if line, ok := g.marchLine(b); ok {
+		lines = append(lines, line)
+	}
+
+	if shown.CapturedBy == g.player {
+		if next, ok := g.sim.Upgrades.Levels[shown.Level+1]; ok && !shown.Upgrading {
+			lines = append(lines, fmt.Sprintf("Upgrade (U): %d troops, %d gold", next.UpgradeCost, next.GoldCost))
+		}
+		lines = append(lines, "Change unit type (T)")
+		return lines
+	}
+	if allied {
+		return append(lines, "Send troops to reinforce")
+	}
+
+	// Unit matchups are per unit type, so list every one selected
+	units := make([]constants.UnitType, 0)
+	for _, source := range g.selected {
+		if !slices.Contains(units, source.Unit) {
+			units = append(units, source.Unit)
+		}
+	}
+	for _, unit := range units {
+		if counter := g.sim.Units.Counter(unit, shown.Unit); counter != 1 {
+			lines = append(lines, fmt.Sprintf("%s vs %s x%.2f", unit, shown.Unit, counter))
+		}
+	}
+
+	// Attack modifiers are per source, so list every distinct one
+	seen := make(map[float64]int)
+	order := make([]float64, 0)
+	for _, source := range g.selected {
+		attack := combat.AttackMultiplier(combat.Elevation(g.sim.Building(source.Id)), &state)
+		if _, ok := seen[attack]; !ok {
+			order = append(order, attack)
+		}
+		seen[attack]++
+	}
+	for _, attack := range order {
+		if attack == 1 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("Uphill attack x%.2f (%d selected)", attack, seen[attack]))
+	}
+
+	return lines
+}
+
+// marchLine estimates how long the selection takes to march to the building,
+// as a range when the sources are at different distances.
+func (g *GameScene) marchLine(b *entities.Building) (string, bool) {
+	fastest, slowest := math.Inf(1), math.Inf(-1)
+	for _, source := range g.selected {
+		if source.Id == b.Id {
+			continue
+		}
+
+		route := g.route(source.Id, b.Id)
+		if route == nil {
+			continue
+		}
+		seconds := g.sim.Dispatch.TravelTime(g.player, g.sim.Building(source.Id).Unit, *route)
+		fastest, slowest = min(fastest, seconds), max(slowest, seconds)
+	}
+	if math.IsInf(fastest, 1) {
+		return "", false
+	}
+
+	if math.Ceil(fastest) == math.Ceil(slowest) {
+		return fmt.Sprintf("March %.0fs", math.Ceil(fastest)), true
+	}
+
+	return fmt.Sprintf("March %.0f-%.0fs", math.Ceil(fastest), math.Ceil(slowest)), true
+}
+
+// route returns the way from one building to another, or nil if there is
+// none. Each pair is only searched once per match.
+func (g *GameScene) route(from, to constants.ID) *sim.Route {
+	key := [2]constants.ID{from, to}
+	if route, ok := g.routes[key]; ok {
+		return route
+	}
+
+	var found *sim.Route
+	if route, err := g.sim.Dispatch.Route(g.sim.Building(from), g.sim.Building(to)); err == nil {
+		found = &route
+	}
+	g.routes[key] = found
+
+	return found
+}
@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+// AbilityService casts the abilities players buy with gold and keeps track of
+// their cooldowns and lasting effects.
+type AbilityService struct {
+	Abilities map[constants.AbilityType]rules.AbilityStats
+	// Cooldowns is how many seconds each player has to wait before casting
+	// each ability again.
+	Cooldowns map[constants.Player]map[constants.AbilityType]float64
+	// Hasted is how many seconds of haste each player's squads have left.
+	Hasted   map[constants.Player]float64
+	bus      *events.Bus
+	economy  *EconomyService
+	teams    Teams
+	tileSize float64
+}
+
+func NewAbilityService(bus *events.Bus, economy *EconomyService, teams Teams, tileSize float64, abilities map[constants.AbilityType]rules.AbilityStats) *AbilityService {
+	return &AbilityService{
+		Abilities: abilities,
+		Cooldowns: make(map[constants.Player]map[constants.AbilityType]float64),
+		Hasted:    make(map[constants.Player]float64),
+		bus:       bus,
+		economy:   economy,
+		teams:     teams,
+		tileSize:  tileSize,
+	}
+}
+
+// Cast pays for the ability and applies it. Building abilities need a target
+// and area abilities land around (x, y); global ones ignore both.
+func (as *AbilityService) Cast(player constants.Player, ability constants.AbilityType, target *Building, x, y float64, buildings []*Building) error {
+	stats, ok := as.Abilities[ability]
+	if !ok {
+		return fmt.Errorf("%q is not an ability", ability)
+	}
+	if cooldown := as.Cooldown(player, ability); cooldown > 0 {
+		return fmt.Errorf("%s is ready in %.0fs", ability, math.Ceil(cooldown))
+	}
+	if stats.Target == constants.BUILDING_TARGET && target == nil {
+		return fmt.Errorf("%s needs a target building", ability)
+	}
+
+	switch ability {
+	case constants.FREEZE:
+		// Freezing always takes a building, whatever target the rules give it
+		if target == nil {
+			return fmt.Errorf("%s needs a target building", ability)
+		}
+		if as.teams.Allied(target.CapturedBy, player) {
+			return fmt.Errorf("Building (%d) is held by %s, an ally", target.Id, target.CapturedBy)
+		}
+	case constants.HEAL:
+		if len(as.inRange(player, x, y, stats.Radius, buildings)) == 0 {
+			return fmt.Errorf("%s has no buildings in range", player)
+		}
+	}
+
+	if err := as.economy.Spend(player, stats.GoldCost); err != nil {
+		return err
+	}
+
+	switch ability {
+	case constants.FREEZE:
+		target.Frozen = stats.Duration
+	case constants.HASTE:
+		as.Hasted[player] = stats.Duration
+	case constants.HEAL:
+		for _, b := range as.inRange(player, x, y, stats.Radius, buildings) {
+			healed := min(float64(b.Occupancy)+stats.Magnitude, float64(max(b.Capacity, b.Occupancy)))
+			SetOccupancy(as.bus, b, uint8(healed))
+		}
+	}
+
+	if as.Cooldowns[player] == nil {
+		as.Cooldowns[player] = make(map[constants.AbilityType]float64)
+	}
+	as.Cooldowns[player][ability] = stats.Cooldown
+
+	return nil
+}
+
+// Cooldown returns how many seconds are left before the player can cast the
+// ability again.
+func (as *AbilityService) Cooldown(player constants.Player, ability constants.AbilityType) float64 {
+	return as.Cooldowns[player][ability]
+}
+
+// SpeedMultiplier returns how much faster the player's squads march right now.
+func (as *AbilityService) SpeedMultiplier(player constants.Player) float64 {
+	if as.Hasted[player] <= 0 {
+		return 1
+	}
+
+	return as.Abilities[constants.HASTE].Magnitude
+}
+
+// Update runs down cooldowns and lasting effects.
+func (as *AbilityService) Update(buildings []*Building, dt float64) {
+	for player, cooldowns := range as.Cooldowns {
+		for ability, cooldown := range cooldowns {
+			if cooldown -= dt; cooldown > 0 {
+				cooldowns[ability] = cooldown
+				continue
+			}
+			delete(cooldowns, ability)
+		}
+		if len(cooldowns) == 0 {
+			delete(as.Cooldowns, player)
+		}
+	}
+
+	for player, hasted := range as.Hasted {
+		if hasted -= dt; hasted > 0 {
+			as.Hasted[player] = hasted
+			continue
+		}
+		delete(as.Hasted, player)
+	}
+
+	for _, b := range buildings {
+		b.Frozen = max(b.Frozen-dt, 0)
+	}
+}
+
+// inRange returns the player's buildings standing within `radius` tiles of
+// (x, y).
+func (as *AbilityService) inRange(player constants.Player, x, y, radius float64, buildings []*Building) []*Building {
+	found := make([]*Building, 0)
+	for _, b := range buildings {
+		bX, bY := b.Base()
+		if b.CapturedBy == player && math.Hypot(bX-x, bY-y) <= radius*as.tileSize {
+			found = append(found, b)
+		}
+	}
+
+	return found
+}
+
+// cloneCooldowns copies cooldowns deeply enough that the copy and the
+// original can't change each other.
+func cloneCooldowns(cooldowns map[constants.Player]map[constants.AbilityType]float64) map[constants.Player]map[constants.AbilityType]float64 {
+	clone := make(map[constants.Player]map[constants.AbilityType]float64, len(cooldowns))
+	for player, c := range cooldowns {
+		clone[player] = maps.Clone(c)
+	}
+
+	return clone
+}
@@ -0,0 +1,23 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+// TestFreezeNeedsBuilding casts freeze without a building after the rules
+// gave it an area target, which has to fail rather than panic.
+func TestFreezeNeedsBuilding(t *testing.T) {
+	r := rules.Default()
+	freeze := r.Abilities[constants.FREEZE]
+	freeze.Target = constants.AREA_TARGET
+	r.Abilities[constants.FREEZE] = freeze
+
+	s := newTestSim(t, Setup{Buildings: testBuildings(), Rules: r})
+	s.Economy.Gold[constants.BLUE] = float64(freeze.GoldCost)
+	if err := s.Abilities.Cast(constants.BLUE, constants.FREEZE, nil, 320, 320, s.Buildings); err == nil {
+		t.Errorf("Cast() error = nil, want an error")
+	}
+}
@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+// ArrivalService decides what happens when a squad reaches its target.
+type ArrivalService struct {
+	// Overflow decides what happens to reinforcements beyond the target's
+	// capacity.
+	Overflow constants.OverflowRule
+	bus      *events.Bus
+	combat   *CombatService
+	dispatch *DispatchService
+	teams    Teams
+}
+
+func NewArrivalService(bus *events.Bus, combat *CombatService, dispatch *DispatchService, teams Teams, r rules.ArrivalRules) *ArrivalService {
+	return &ArrivalService{
+		Overflow: r.Overflow,
+		bus:      bus,
+		combat:   combat,
+		dispatch: dispatch,
+		teams:    teams,
+	}
+}
+
+// Resolve settles a squad that reached its target, reinforcing it if it's
+// held by the squad's owner or an ally and attacking it otherwise. The source
+// is only needed to bounce overflow back to, and may be nil.
+func (as *ArrivalService) Resolve(s *Squad, source, target *Building) {
+	if as.teams.Allied(target.CapturedBy, s.Owner) {
+		as.reinforce(s, source, target)
+		return
+	}
+
+	as.combat.Assault(s, source, target)
+}
+
+// reinforce adds the squad's troops to the garrison up to its capacity and
+// applies the overflow rule to the rest.
+func (as *ArrivalService) reinforce(s *Squad, source, target *Building) {
+	room := max(int(target.Capacity)-int(target.Occupancy), 0)
+	taken := min(int(s.Troops), room)
+	overflow := int(s.Troops) - taken
+	SetOccupancy(as.bus, target, target.Occupancy+uint8(taken))
+	if overflow == 0 {
+		return
+	}
+
+	rule := as.Overflow
+	// Bounced troops only get one trip back, and need somewhere to go
+	if rule == constants.BOUNCE && (s.Returning || source == nil || source == target || source.CapturedBy != s.Owner) {
+		rule = constants.WASTE
+	}
+
+	switch rule {
+	case constants.BOUNCE:
+		if _, err := as.dispatch.Return(s.Owner, target, source, s.Unit, uint8(overflow)); err != nil {
+			fmt.Printf("Unable to bounce troops: %v\n", err)
+		}
+	case constants.KEEP:
+		occupancy := int(target.Occupancy) + overflow
+		SetOccupancy(as.bus, target, uint8(min(occupancy, math.MaxUint8)))
+	}
+}
@@ -0,0 +1,22 @@
+package sim
+
+import (
+	"github.com/ehutchllew/autoarmy/events"
+)
+
+// SetOccupancy updates the building's garrison and announces the change. Every
+// runtime change to `Occupancy` should go through here so listeners never
+// miss one.
+func SetOccupancy(bus *events.Bus, b *Building, occupancy uint8) {
+	if b.Occupancy == occupancy {
+		return
+	}
+
+	from := b.Occupancy
+	b.Occupancy = occupancy
+	bus.Publish(events.OccupancyChanged{
+		Building: b.Id,
+		From:     from,
+		To:       occupancy,
+	})
+}
@@ -0,0 +1,272 @@
+package sim
+
+import (
+	"math"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/rules"
+	"github.com/ehutchllew/autoarmy/spatial"
+)
+
+type CombatService struct {
+	BaseTowerRange float64 // Tiles
+	// CaptureSurvivors is the least troops left garrisoning a building after
+	// it's captured, however close the fight was.
+	CaptureSurvivors uint8
+	// ElevationDefense is the extra defense multiplier per elevation level a
+	// building stands on.
+	ElevationDefense float64
+	// EngageRadius is how close (in pixels) two hostile squads have to get
+	// before they fight in the field.
+	EngageRadius float64
+	// MinAttack is the floor for the attack multiplier no matter how far
+	// uphill an attack goes.
+	MinAttack float64
+	// PlateauTowerRange is the extra tower range, in tiles, per elevation
+	// level the tower stands on.
+	PlateauTowerRange float64
+	// TowerFireInterval is the seconds between a tower's shots. Each shot
+	// kills one troop of the closest hostile squad in range.
+	TowerFireInterval float64
+	// UphillPenalty is how much of its attack a squad loses per elevation
+	// level it has to climb from its source to the target.
+	UphillPenalty float64
+	bus           *events.Bus
+	handicaps     Handicaps
+	nav           *navigation.Grid
+	teams         Teams
+	towerCooldown map[constants.ID]float64
+	units         *UnitService
+	upgrades      *UpgradeService
+}
+
+func NewCombatService(bus *events.Bus, handicaps Handicaps, nav *navigation.Grid, teams Teams, units *UnitService, upgrades *UpgradeService, r rules.CombatRules) *CombatService {
+	return &CombatService{
+		BaseTowerRange:    r.BaseTowerRange,
+		CaptureSurvivors:  r.CaptureSurvivors,
+		ElevationDefense:  r.ElevationDefense,
+		EngageRadius:      r.EngageRadius,
+		MinAttack:         r.MinAttack,
+		PlateauTowerRange: r.PlateauTowerRange,
+		TowerFireInterval: r.TowerFireInterval,
+		UphillPenalty:     r.UphillPenalty,
+		bus:               bus,
+		handicaps:         handicaps,
+		nav:               nav,
+		teams:             teams,
+		towerCooldown:     make(map[constants.ID]float64),
+		units:             units,
+		upgrades:          upgrades,
+	}
+}
+
+// Assault fights the squad against the target's garrison. Each attacker is
+// worth `AttackMultiplier` troops and each defender `DefenseMultiplier`, both
+// scaled by how their unit types match up and their owners' handicaps. Unless
+// the defenders outweigh the attackers, the garrison falls and the building
+// is captured, the survivors becoming its new garrison and keeping the
+// building's unit type. The source is only used for the elevation the attack
+// came from, and may be nil.
+func (cs *CombatService) Assault(s *Squad, source, target *Building) {
+	// Getting attacked cancels whatever the defenders were building
+	if target.Upgrading {
+		cs.upgrades.Interrupt(target)
+	}
+
+	fromElevation := cs.nav.ElevationAt(s.X, s.Y)
+	if source != nil {
+		fromElevation = cs.Elevation(source)
+	}
+
+	attack := cs.AttackMultiplier(fromElevation, target) * cs.strength(s.Owner, s.Unit, target.Unit)
+	defense := cs.DefenseMultiplier(target) * cs.strength(target.CapturedBy, target.Unit, s.Unit)
+	attackers := float64(s.Troops) * attack
+	defenders := float64(target.Occupancy) * defense
+
+	if attackers < defenders {
+		survivors := math.Ceil(float64(target.Occupancy) - attackers/defense)
+		SetOccupancy(cs.bus, target, uint8(max(survivors, 0)))
+		return
+	}
+
+	survivors := math.Max(math.Floor((attackers-defenders)/attack), float64(cs.CaptureSurvivors))
+	SetOccupancy(cs.bus, target, uint8(survivors))
+	cs.Capture(target, s.Owner)
+}
+
+// AttackMultiplier is how much each troop attacking the target from the given
+// elevation is worth. Attacking downhill or on level ground carries no bonus,
+// but every level climbed costs `UphillPenalty`.
+func (cs *CombatService) AttackMultiplier(fromElevation uint8, target *Building) float64 {
+	climb := int(cs.Elevation(target)) - int(fromElevation)
+	if climb <= 0 {
+		return 1
+	}
+
+	return math.Max(1-cs.UphillPenalty*float64(climb), cs.MinAttack)
+}
+
+// FieldBattles fights every pair of hostile (not allied) squads that have run
+// into each other. The loser is wiped out and the winner keeps marching toward
+// its target with whoever survived. It returns the squads that were wiped out,
+// which the caller should stop tracking.
+func (cs *CombatService) FieldBattles(squads []*Squad, index *spatial.Grid[*Squad]) []*Squad {
+	destroyed := make([]*Squad, 0)
+	isDestroyed := func(s *Squad) bool {
+		return slices.Contains(destroyed, s)
+	}
+
+	for _, s := range squads {
+		for _, enemy := range index.QueryRadius(s.X, s.Y, cs.EngageRadius) {
+			if isDestroyed(s) {
+				break
+			}
+
+			if enemy == s || cs.teams.Allied(enemy.Owner, s.Owner) || isDestroyed(enemy) {
+				continue
+			}
+			if math.Hypot(enemy.X-s.X, enemy.Y-s.Y) > cs.EngageRadius {
+				continue
+			}
+
+			destroyed = append(destroyed, cs.skirmish(s, enemy)...)
+		}
+	}
+
+	for _, s := range destroyed {
+		cs.bus.Publish(events.EntityDestroyed{
+			Id:   s.Id,
+			Type: constants.SQUAD,
+		})
+	}
+
+	return destroyed
+}
+
+// Capture hands the building over to a new owner.
+func (cs *CombatService) Capture(b *Building, player constants.Player) {
+	if b.CapturedBy == player {
+		return
+	}
+
+	from := b.CapturedBy
+	b.CapturedBy = player
+	cs.bus.Publish(events.BuildingCaptured{
+		Building: b.Id,
+		From:     from,
+		To:       player,
+	})
+}
+
+// DefenseMultiplier is how many attackers each defender of the building is
+// worth, from its archetype and the elevation it stands on.
+func (cs *CombatService) DefenseMultiplier(b *Building) float64 {
+	archetype, elevation := cs.DefenseModifiers(b)
+	return archetype * elevation
+}
+
+// DefenseModifiers breaks `DefenseMultiplier` down into the part from the
+// building's archetype and the part from the high ground it stands on.
+func (cs *CombatService) DefenseModifiers(b *Building) (float64, float64) {
+	archetype := cs.upgrades.Levels[b.Level].Defense
+	if archetype == 0 {
+		archetype = 1
+	}
+
+	return archetype, 1 + cs.ElevationDefense*float64(cs.Elevation(b))
+}
+
+// Elevation returns the level of the ground the building stands on.
+func (cs *CombatService) Elevation(b *Building) uint8 {
+	return cs.nav.ElevationAt(b.Base())
+}
+
+// TowerFire lets every tower shoot at the closest hostile squad within its
+// range once per `TowerFireInterval`. Shooting is what a tower's range is for,
+// and so what the plateau bonus in `TowerRange` extends. It returns the squads
+// that lost their last troop, which the caller should stop tracking.
+func (cs *CombatService) TowerFire(buildings []*Building, index *spatial.Grid[*Squad], dt float64) []*Squad {
+	destroyed := make([]*Squad, 0)
+	for _, b := range buildings {
+		towerRange := cs.TowerRange(b)
+		if towerRange == 0 || b.CapturedBy == constants.NONE || b.Occupancy == 0 {
+			delete(cs.towerCooldown, b.Id)
+			continue
+		}
+
+		cooldown := cs.towerCooldown[b.Id] - dt
+		if cooldown > 0 {
+			cs.towerCooldown[b.Id] = cooldown
+			continue
+		}
+
+		x, y := b.Base()
+		radius := towerRange * cs.nav.TileSize
+		var closest *Squad
+		closestDist := math.Inf(1)
+		for _, s := range index.QueryRadius(x, y, radius) {
+			if cs.teams.Allied(s.Owner, b.CapturedBy) || s.Troops == 0 {
+				continue
+			}
+
+			if dist := math.Hypot(s.X-x, s.Y-y); dist <= radius && dist < closestDist {
+				closest, closestDist = s, dist
+			}
+		}
+		if closest == nil {
+			cs.towerCooldown[b.Id] = 0
+			continue
+		}
+
+		closest.Troops--
+		if closest.Troops == 0 {
+			destroyed = append(destroyed, closest)
+			cs.bus.Publish(events.EntityDestroyed{
+				Id:   closest.Id,
+				Type: constants.SQUAD,
+			})
+		}
+		cs.towerCooldown[b.Id] = cs.TowerFireInterval
+	}
+
+	return destroyed
+}
+
+// TowerRange returns how far, in tiles, the building can shoot, or 0 if it
+// isn't a tower. Towers on plateaus shoot further, by `PlateauTowerRange` per
+// level. How far they see is up to the vision rules.
+func (cs *CombatService) TowerRange(b *Building) float64 {
+	if b.Level != constants.TOWER_LEVEL {
+		return 0
+	}
+
+	return cs.BaseTowerRange + cs.PlateauTowerRange*float64(cs.Elevation(b))
+}
+
+// skirmish fights two squads by troop count and unit type and returns the
+// ones wiped out. Evenly matched squads wipe each other out.
+func (cs *CombatService) skirmish(a, b *Squad) []*Squad {
+	aAttack, bAttack := cs.strength(a.Owner, a.Unit, b.Unit), cs.strength(b.Owner, b.Unit, a.Unit)
+	aStrength := float64(a.Troops) * aAttack
+	bStrength := float64(b.Troops) * bAttack
+
+	switch {
+	case aStrength > bStrength:
+		a.Troops = uint8(math.Max(math.Ceil((aStrength-bStrength)/aAttack), 1))
+		return []*Squad{b}
+	case bStrength > aStrength:
+		b.Troops = uint8(math.Max(math.Ceil((bStrength-aStrength)/bAttack), 1))
+		return []*Squad{a}
+	}
+
+	return []*Squad{a, b}
+}
+
+// strength is how much one of the player's troops of the given unit type is
+// worth against the other unit type.
+func (cs *CombatService) strength(player constants.Player, unit, against constants.UnitType) float64 {
+	return cs.units.Strength(unit, against) * cs.handicaps.Get(player).Strength
+}
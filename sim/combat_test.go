@@ -0,0 +1,134 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/spatial"
+)
+
+// The default rules give knights a strength of 1 against each other, houses
+// a defense of 1, and each elevation level +0.25 defense and -0.25 attack.
+func TestAssault(t *testing.T) {
+	tests := []struct {
+		name      string
+		attackers uint8
+		defenders uint8
+		elevation uint8 // Of the ground the target stands on
+		owner     constants.Player
+		occupancy uint8
+	}{
+		{name: "captures", attackers: 10, defenders: 4, owner: constants.BLUE, occupancy: 6},
+		{name: "held", attackers: 3, defenders: 5, owner: constants.RED, occupancy: 2},
+		{name: "even fight captures", attackers: 5, defenders: 5, owner: constants.BLUE, occupancy: 1},
+		// 10 x 0.75 attack against 4 x 1.25 defense leaves 2.5 of attack
+		{name: "captures uphill", attackers: 10, defenders: 4, elevation: 1, owner: constants.BLUE, occupancy: 3},
+		// 7 x 0.75 attack against 4 x 1.25 defense leaves 0.25 of attack
+		{name: "leaves at least one survivor", attackers: 7, defenders: 4, elevation: 1, owner: constants.BLUE, occupancy: 1},
+		{name: "held uphill", attackers: 6, defenders: 5, elevation: 1, owner: constants.RED, occupancy: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nav := navigation.NewGrid(20, 20, constants.Tilesize)
+			x, y := nav.CellAt(320, 319)
+			nav.SetElevation(x, y, tt.elevation)
+			s := newTestSim(t, Setup{
+				Buildings: []Building{{Id: 1, CapturedBy: constants.RED, Level: constants.HOUSE_LEVEL, Occupancy: tt.defenders, X: 320, Y: 320}},
+				Nav:       nav,
+			})
+
+			target := s.Building(1)
+			squad := &Squad{Owner: constants.BLUE, Troops: tt.attackers, Unit: constants.KNIGHT, X: 320, Y: 336}
+			s.Combat.Assault(squad, nil, target)
+
+			if target.CapturedBy != tt.owner || target.Occupancy != tt.occupancy {
+				t.Errorf("Assault() left %s with %d troops, want %s with %d", target.CapturedBy, target.Occupancy, tt.owner, tt.occupancy)
+			}
+		})
+	}
+}
+
+func TestFieldBattles(t *testing.T) {
+	tests := []struct {
+		name   string
+		teams  string
+		blue   uint8
+		red    uint8
+		apart  float64 // Pixels between the squads
+		lost   []constants.Player
+		troops map[constants.Player]uint8
+	}{
+		{name: "larger squad wins", blue: 10, red: 4, apart: 16, lost: []constants.Player{constants.RED}, troops: map[constants.Player]uint8{constants.BLUE: 6}},
+		{name: "even squads wipe each other out", blue: 5, red: 5, apart: 16, lost: []constants.Player{constants.BLUE, constants.RED}},
+		{name: "out of range", blue: 10, red: 4, apart: 200, troops: map[constants.Player]uint8{constants.BLUE: 10, constants.RED: 4}},
+		{name: "allies pass each other", teams: "BLUE,RED", blue: 10, red: 4, apart: 16, troops: map[constants.Player]uint8{constants.BLUE: 10, constants.RED: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams, err := ParseTeams(tt.teams)
+			if err != nil {
+				t.Fatalf("ParseTeams() error = %v", err)
+			}
+			s := newTestSim(t, Setup{Teams: teams})
+
+			squads := []*Squad{
+				{Id: 1, Owner: constants.BLUE, Troops: tt.blue, Unit: constants.KNIGHT, X: 320, Y: 320},
+				{Id: 2, Owner: constants.RED, Troops: tt.red, Unit: constants.KNIGHT, X: 320 + tt.apart, Y: 320},
+			}
+			index := spatial.NewGrid[*Squad](constants.Tilesize * 2)
+			for _, sq := range squads {
+				index.Insert(sq, spatial.NewRect(sq.X, sq.Y, 0, 0))
+			}
+
+			var lost []constants.Player
+			for _, sq := range s.Combat.FieldBattles(squads, index) {
+				lost = append(lost, sq.Owner)
+			}
+			slices.Sort(lost)
+			if !slices.Equal(lost, tt.lost) {
+				t.Errorf("FieldBattles() wiped out %v, want %v", lost, tt.lost)
+			}
+
+			for _, sq := range squads {
+				if want, ok := tt.troops[sq.Owner]; ok && sq.Troops != want {
+					t.Errorf("%s squad has %d troops, want %d", sq.Owner, sq.Troops, want)
+				}
+			}
+		})
+	}
+}
+
+// TestTowerSkipsSquadsLostInTheField has a tower watch a field battle and
+// expects it to shoot the squad still standing rather than the one just
+// wiped out.
+func TestTowerSkipsSquadsLostInTheField(t *testing.T) {
+	s := newTestSim(t, Setup{
+		Buildings: []Building{{Id: 1, CapturedBy: constants.RED, Level: constants.TOWER_LEVEL, Occupancy: 10, X: 320, Y: 320}},
+	})
+
+	// The squads hold still while they wait to march
+	squads := []*Squad{
+		{Delay: 10, Id: 2, Owner: constants.BLUE, Troops: 1, Unit: constants.KNIGHT, X: 320, Y: 400},
+		{Delay: 10, Id: 3, Owner: constants.RED, Troops: 5, Unit: constants.KNIGHT, X: 330, Y: 400},
+		{Delay: 10, Id: 4, Owner: constants.BLUE, Troops: 5, Unit: constants.KNIGHT, X: 320, Y: 480},
+	}
+	s.Dispatch.Squads = append(s.Dispatch.Squads, squads...)
+
+	destroyed := make(map[constants.ID]int)
+	events.Subscribe(s.Bus, func(e events.EntityDestroyed) {
+		destroyed[e.Id]++
+	})
+	s.Step()
+
+	if destroyed[2] != 1 {
+		t.Errorf("Squad lost in the field was destroyed %d times, want 1", destroyed[2])
+	}
+	if troops := squads[2].Troops; troops != 4 {
+		t.Errorf("Squad in the tower's range has %d troops, want 4", troops)
+	}
+}
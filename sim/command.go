@@ -0,0 +1,24 @@
+package sim
+
+import "github.com/ehutchllew/autoarmy/constants"
+
+// Command is a player's order. Commands are the only way anything outside
+// the simulation changes it, so replaying the same commands on the same ticks
+// replays the same match.
+type Command struct {
+	// Ability is what an ability order casts.
+	Ability constants.AbilityType `json:"ability,omitempty"`
+	Player  constants.Player      `json:"player"`
+	// Sources are the buildings a send order goes out from.
+	Sources []constants.ID `json:"sources,omitempty"`
+	// Target is where a send order goes, the building to upgrade or switch
+	// unit types, or the building an ability is cast on.
+	Target constants.ID          `json:"target"`
+	Tick   uint64                `json:"tick"` // The tick the command was applied on
+	Type   constants.CommandType `json:"type"`
+	// Unit is the type a unit order switches the target to.
+	Unit constants.UnitType `json:"unit,omitempty"`
+	// X and Y are where an area ability lands.
+	X float64 `json:"x,omitempty"`
+	Y float64 `json:"y,omitempty"`
+}
@@ -0,0 +1,308 @@
+package sim
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+type DispatchService struct {
+	// NextId is handed to the next squad sent. It should start above every
+	// object ID the map uses.
+	NextId constants.ID
+	// SendRatio is the share of the source's occupancy sent with each order.
+	SendRatio  float64
+	SquadSpeed float64 // Pixels per second
+	Squads     []*Squad
+	// StaggerDelay is the gap in seconds between squads leaving together on a
+	// single multi-building order, so they don't march on top of each other.
+	StaggerDelay float64
+	abilities    *AbilityService
+	bus          *events.Bus
+	handicaps    Handicaps
+	nav          *navigation.Grid
+	units        *UnitService
+}
+
+func NewDispatchService(abilities *AbilityService, bus *events.Bus, handicaps Handicaps, nav *navigation.Grid, units *UnitService, r rules.DispatchRules) *DispatchService {
+	return &DispatchService{
+		NextId:       1,
+		SendRatio:    r.SendRatio,
+		SquadSpeed:   r.SquadSpeed,
+		Squads:       make([]*Squad, 0),
+		StaggerDelay: r.StaggerDelay,
+		abilities:    abilities,
+		bus:          bus,
+		handicaps:    handicaps,
+		nav:          nav,
+		units:        units,
+	}
+}
+
+// Route is the way a squad marches to its target.
+type Route struct {
+	Path  []Point // Waypoints after `Start`, the last being at the target
+	Start Point
+}
+
+// Length returns how many pixels marching the route covers.
+func (r Route) Length() float64 {
+	var length float64
+	from := r.Start
+	for _, p := range r.Path {
+		length += math.Hypot(p.X-from.X, p.Y-from.Y)
+		from = p
+	}
+
+	return length
+}
+
+// Send takes `SendRatio` of the source's garrison right away and sets it
+// marching toward the target as a new squad.
+func (ds *DispatchService) Send(player constants.Player, source, target *Building) (*Squad, error) {
+	route, err := ds.plan(player, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.send(player, source, target, route), nil
+}
+
+// SendMany sends from every source to the target on the same update. The
+// sources with the shortest march to the target leave first, the rest follow
+// `StaggerDelay` apart. Sources that can't send are skipped and their errors
+// returned.
+func (ds *DispatchService) SendMany(player constants.Player, sources []*Building, target *Building) ([]*Squad, []error) {
+	type order struct {
+		route  Route
+		source *Building
+	}
+
+	orders := make([]order, 0, len(sources))
+	errs := make([]error, 0)
+	for _, source := range sources {
+		if source == target {
+			continue
+		}
+
+		route, err := ds.plan(player, source, target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		orders = append(orders, order{route: route, source: source})
+	}
+	slices.SortStableFunc(orders, func(a, b order) int {
+		return cmp.Compare(a.route.Length(), b.route.Length())
+	})
+
+	squads := make([]*Squad, 0, len(orders))
+	for _, o := range orders {
+		squad := ds.send(player, o.source, target, o.route)
+		squad.Delay = float64(len(squads)) * ds.StaggerDelay
+		squads = append(squads, squad)
+	}
+
+	return squads, errs
+}
+
+// Route finds the way from the source to the target. Squads leave and arrive
+// at the walkable cells beside each building nearest its entrance that still
+// have a way through.
+func (ds *DispatchService) Route(source, target *Building) (Route, error) {
+	x, y := source.Entrance()
+	route, ok := ds.route(navigation.Endpoint{Building: source.Id, X: x, Y: y}, target)
+	if !ok {
+		return Route{}, fmt.Errorf("Building (%d) has no way to building (%d)", source.Id, target.Id)
+	}
+
+	return route, nil
+}
+
+// TravelTime returns how many seconds the player's troops of the given unit
+// type take to march the route, without haste.
+func (ds *DispatchService) TravelTime(player constants.Player, unit constants.UnitType, route Route) float64 {
+	return route.Length() / ds.speed(player, unit)
+}
+
+// Return marches troops that couldn't be taken in by `from` back to `to`.
+func (ds *DispatchService) Return(player constants.Player, from, to *Building, unit constants.UnitType, troops uint8) (*Squad, error) {
+	route, err := ds.Route(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	squad := ds.march(player, from.Id, route, to, unit, troops)
+	squad.Returning = true
+
+	return squad, nil
+}
+
+// Remove stops tracking the squad, e.g. after it was wiped out in the field.
+func (ds *DispatchService) Remove(squad *Squad) {
+	ds.Squads = slices.DeleteFunc(ds.Squads, func(s *Squad) bool {
+		return s == squad
+	})
+}
+
+// Squad returns the marching squad with the given ID, or nil if there is none.
+func (ds *DispatchService) Squad(id constants.ID) *Squad {
+	for _, s := range ds.Squads {
+		if s.Id == id {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// SpawnAt sets a squad that came from nowhere marching from (x, y) toward the
+// target, e.g. reinforcements a map script sends in. The source is who the
+// squad counts as coming from, 0 if nobody, and the squad leaves from beside
+// it if there is one.
+func (ds *DispatchService) SpawnAt(player constants.Player, source constants.ID, x, y float64, target *Building, unit constants.UnitType, troops uint8) (*Squad, error) {
+	route, ok := ds.route(navigation.Endpoint{Building: source, X: x, Y: y}, target)
+	if !ok {
+		return nil, fmt.Errorf("Building (%d) can't be reached from (%.0f, %.0f)", target.Id, x, y)
+	}
+
+	return ds.march(player, source, route, target, unit, troops), nil
+}
+
+// march sets a new squad off along the route.
+func (ds *DispatchService) march(player constants.Player, source constants.ID, route Route, target *Building, unit constants.UnitType, troops uint8) *Squad {
+	squad := &Squad{
+		Id:     ds.NextId,
+		Owner:  player,
+		Path:   route.Path,
+		PrevX:  route.Start.X,
+		PrevY:  route.Start.Y,
+		Source: source,
+		Speed:  ds.speed(player, unit),
+		Target: target.Id,
+		Troops: troops,
+		Unit:   unit,
+		X:      route.Start.X,
+		Y:      route.Start.Y,
+	}
+	ds.NextId++
+	ds.Squads = append(ds.Squads, squad)
+
+	ds.bus.Publish(events.EntitySpawned{
+		Id:    squad.Id,
+		Owner: squad.Owner,
+		Type:  constants.SQUAD,
+	})
+	ds.bus.Publish(events.SquadDispatched{
+		Owner:  squad.Owner,
+		Source: squad.Source,
+		Squad:  squad.Id,
+		Target: squad.Target,
+		Troops: squad.Troops,
+	})
+
+	return squad
+}
+
+// Update marches every squad along its path, hasted or not, and returns the
+// ones that reached their target this update. Arrived squads are no longer
+// tracked, resolving what happens at the target is up to the caller.
+func (ds *DispatchService) Update(dt float64) []*Squad {
+	arrived := make([]*Squad, 0)
+	marching := make([]*Squad, 0, len(ds.Squads))
+	for _, s := range ds.Squads {
+		step := s.Speed * ds.abilities.SpeedMultiplier(s.Owner) * dt
+		if s.Delay > 0 {
+			s.Delay -= dt
+			marching = append(marching, s)
+			continue
+		}
+		for step > 0 && len(s.Path) > 0 {
+			next := s.Path[0]
+			dX, dY := next.X-s.X, next.Y-s.Y
+			dist := math.Hypot(dX, dY)
+			if dist <= step {
+				s.X, s.Y = next.X, next.Y
+				s.Path = s.Path[1:]
+				step -= dist
+				continue
+			}
+
+			s.X += dX / dist * step
+			s.Y += dY / dist * step
+			step = 0
+		}
+
+		if len(s.Path) > 0 {
+			marching = append(marching, s)
+			continue
+		}
+
+		arrived = append(arrived, s)
+		ds.bus.Publish(events.SquadArrived{
+			Owner:  s.Owner,
+			Squad:  s.Id,
+			Target: s.Target,
+			Troops: s.Troops,
+		})
+		ds.bus.Publish(events.EntityDestroyed{
+			Id:   s.Id,
+			Type: constants.SQUAD,
+		})
+	}
+	ds.Squads = marching
+
+	return arrived
+}
+
+// speed is how many pixels per second the player's troops of the given unit
+// type march, without haste.
+func (ds *DispatchService) speed(player constants.Player, unit constants.UnitType) float64 {
+	return ds.SquadSpeed * ds.units.Speed(unit) * ds.handicaps.Get(player).Speed
+}
+
+// plan checks the source can send to the target and finds the way there.
+func (ds *DispatchService) plan(player constants.Player, source, target *Building) (Route, error) {
+	if source.CapturedBy != player {
+		return Route{}, fmt.Errorf("Building (%d) is not owned by %s", source.Id, player)
+	}
+	if source == target {
+		return Route{}, fmt.Errorf("Building (%d) can't send troops to itself", source.Id)
+	}
+	if source.Occupancy == 0 {
+		return Route{}, fmt.Errorf("Building (%d) has no troops to send", source.Id)
+	}
+
+	return ds.Route(source, target)
+}
+
+// route finds the way from the endpoint to the target's door.
+func (ds *DispatchService) route(from navigation.Endpoint, target *Building) (Route, bool) {
+	x, y := target.Entrance()
+	start, found, ok := ds.nav.FindPath(from, navigation.Endpoint{Building: target.Id, X: x, Y: y})
+	if !ok {
+		return Route{}, false
+	}
+
+	path := make([]Point, 0, len(found))
+	for _, p := range found {
+		path = append(path, Point{X: p.X, Y: p.Y})
+	}
+
+	return Route{Path: path, Start: Point{X: start.X, Y: start.Y}}, true
+}
+
+// send takes `SendRatio` of the source's garrison and marches it along the
+// route.
+func (ds *DispatchService) send(player constants.Player, source, target *Building, route Route) *Squad {
+	troops := uint8(math.Ceil(float64(source.Occupancy) * ds.SendRatio))
+	SetOccupancy(ds.bus, source, source.Occupancy-troops)
+
+	return ds.march(player, source.Id, route, target, source.Unit, troops)
+}
@@ -0,0 +1,70 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+// EconomyService keeps every player's gold. Gold is earned by the houses a
+// player owns and spent on upgrades, unit types and abilities.
+type EconomyService struct {
+	// Gold is each player's balance. It accumulates fractionally, but only
+	// whole gold can be spent.
+	Gold         map[constants.Player]float64
+	GoldPerHouse float64 // Gold per second
+}
+
+func NewEconomyService(r rules.EconomyRules) *EconomyService {
+	return &EconomyService{
+		Gold:         make(map[constants.Player]float64),
+		GoldPerHouse: r.GoldPerHouse,
+	}
+}
+
+// Start gives every player their starting gold.
+func (es *EconomyService) Start(players []constants.Player, startingGold int) {
+	for _, p := range players {
+		es.Gold[p] = float64(startingGold)
+	}
+}
+
+// Balance returns the whole gold the player can spend.
+func (es *EconomyService) Balance(player constants.Player) int {
+	return int(math.Floor(es.Gold[player]))
+}
+
+// Income returns how much gold per second the player is earning.
+func (es *EconomyService) Income(player constants.Player, buildings []*Building) float64 {
+	var houses int
+	for _, b := range buildings {
+		if b.CapturedBy == player && b.Level == constants.HOUSE_LEVEL {
+			houses++
+		}
+	}
+
+	return float64(houses) * es.GoldPerHouse
+}
+
+// Spend takes the gold from the player, or fails if they can't afford it.
+func (es *EconomyService) Spend(player constants.Player, amount int) error {
+	if balance := es.Balance(player); balance < amount {
+		return fmt.Errorf("%s needs %d gold, has %d", player, amount, balance)
+	}
+
+	es.Gold[player] -= float64(amount)
+	return nil
+}
+
+// Update pays every player for the houses they own.
+func (es *EconomyService) Update(buildings []*Building, dt float64) {
+	for _, b := range buildings {
+		if b.CapturedBy == constants.NONE || b.Level != constants.HOUSE_LEVEL {
+			continue
+		}
+
+		es.Gold[b.CapturedBy] += es.GoldPerHouse * dt
+	}
+}
@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// HandicapPrefix starts the names of map properties that set a player's
+// handicap, e.g. "handicap.RED.production".
+const HandicapPrefix = "handicap."
+
+// Handicap scales what a player's side is capable of, to even out matches
+// between players of different skill or to tune how hard an AI plays. Fields
+// left at 0 count as 1.
+type Handicap struct {
+	Occupancy  float64 `json:"occupancy"`  // Starting garrisons
+	Production float64 `json:"production"` // Troop production rate
+	Speed      float64 `json:"speed"`      // Squad marching speed
+	Strength   float64 `json:"strength"`   // Attack and defense in combat
+}
+
+// Handicaps are per player. Players without one play unhandicapped.
+type Handicaps map[constants.Player]Handicap
+
+// ParseHandicaps reads every handicap property out of the map's properties.
+func ParseHandicaps(props map[string]any) (Handicaps, error) {
+	handicaps := make(Handicaps)
+	for name, value := range props {
+		path, ok := strings.CutPrefix(name, HandicapPrefix)
+		if !ok {
+			continue
+		}
+
+		playerName, field, ok := strings.Cut(path, ".")
+		player := constants.Player(strings.ToUpper(playerName))
+		if !ok || !slices.Contains(constants.Players, player) {
+			return nil, fmt.Errorf("%q doesn't name a player's handicap", name)
+		}
+		multiplier, ok := value.(float64)
+		if !ok || multiplier <= 0 {
+			return nil, fmt.Errorf("%s must be a positive number", name)
+		}
+
+		h := handicaps[player]
+		switch field {
+		case "occupancy":
+			h.Occupancy = multiplier
+		case "production":
+			h.Production = multiplier
+		case "speed":
+			h.Speed = multiplier
+		case "strength":
+			h.Strength = multiplier
+		default:
+			return nil, fmt.Errorf("%q is not a handicap", field)
+		}
+		handicaps[player] = h
+	}
+
+	return handicaps, nil
+}
+
+// Get returns the player's handicap with every unset field at 1. Neutrals
+// are never handicapped.
+func (h Handicaps) Get(player constants.Player) Handicap {
+	handicap := h[player]
+	if player == constants.NONE {
+		handicap = Handicap{}
+	}
+
+	for _, f := range []*float64{&handicap.Occupancy, &handicap.Production, &handicap.Speed, &handicap.Strength} {
+		if *f == 0 {
+			*f = 1
+		}
+	}
+
+	return handicap
+}
@@ -0,0 +1,132 @@
+package sim
+
+import (
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+)
+
+// MatchService tracks who is still in the match. A player is eliminated once
+// they own no buildings and have no squads marching, and the match ends when
+// at most one team is left standing. The whole team wins, including allies
+// eliminated along the way. A team that started alone, e.g. against neutrals
+// only, has to be ended by the map unless it's wiped out.
+type MatchService struct {
+	Eliminated []constants.Player // In the order they were knocked out
+	Ended      bool
+	Players    []constants.Player // Everyone who started the match
+	Winners    []constants.Player
+	bus        *events.Bus
+	teams      Teams
+}
+
+func NewMatchService(bus *events.Bus, teams Teams) *MatchService {
+	return &MatchService{
+		Eliminated: make([]constants.Player, 0),
+		Players:    make([]constants.Player, 0),
+		Winners:    make([]constants.Player, 0),
+		bus:        bus,
+		teams:      teams,
+	}
+}
+
+// Start records every player owning a building or squad as a participant.
+func (ms *MatchService) Start(buildings []*Building, squads []*Squad) {
+	owners := make([]constants.Player, 0, len(buildings)+len(squads))
+	for _, b := range buildings {
+		owners = append(owners, b.CapturedBy)
+	}
+	for _, s := range squads {
+		owners = append(owners, s.Owner)
+	}
+
+	for _, p := range owners {
+		if p != constants.NONE && !slices.Contains(ms.Players, p) {
+			ms.Players = append(ms.Players, p)
+		}
+	}
+}
+
+// Update eliminates players with nothing left and reports whether the match
+// ended on this update.
+func (ms *MatchService) Update(buildings []*Building, squads []*Squad) bool {
+	if ms.Ended || len(ms.Players) == 0 {
+		return false
+	}
+
+	alive := make(map[constants.Player]bool)
+	for _, b := range buildings {
+		alive[b.CapturedBy] = true
+	}
+	for _, s := range squads {
+		alive[s.Owner] = true
+	}
+
+	remaining := make([]constants.Player, 0)
+	for _, p := range ms.Players {
+		if slices.Contains(ms.Eliminated, p) {
+			continue
+		}
+		if !alive[p] {
+			ms.Eliminated = append(ms.Eliminated, p)
+			ms.bus.Publish(events.PlayerEliminated{Player: p})
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+
+	if !ms.contested() && len(remaining) > 0 {
+		return false
+	}
+	for _, p := range remaining {
+		if !ms.teams.Allied(p, remaining[0]) {
+			return false
+		}
+	}
+
+	ms.Ended = true
+	if len(remaining) > 0 {
+		for _, p := range ms.Players {
+			if ms.teams.Allied(p, remaining[0]) {
+				ms.Winners = append(ms.Winners, p)
+			}
+		}
+	}
+	ms.bus.Publish(events.MatchEnded{
+		Winners: slices.Clone(ms.Winners),
+	})
+
+	return true
+}
+
+// End ends the match early with the player's team as the winners, or with
+// nobody winning for NONE. It reports whether the match was still going.
+func (ms *MatchService) End(winner constants.Player) bool {
+	if ms.Ended {
+		return false
+	}
+
+	ms.Ended = true
+	for _, p := range ms.Players {
+		if winner != constants.NONE && ms.teams.Allied(p, winner) {
+			ms.Winners = append(ms.Winners, p)
+		}
+	}
+	ms.bus.Publish(events.MatchEnded{
+		Winners: slices.Clone(ms.Winners),
+	})
+
+	return true
+}
+
+// contested reports whether more than one team started the match.
+func (ms *MatchService) contested() bool {
+	return slices.ContainsFunc(ms.Players, func(p constants.Player) bool {
+		return !ms.teams.Allied(p, ms.Players[0])
+	})
+}
+
+func (ms *MatchService) IsEliminated(player constants.Player) bool {
+	return slices.Contains(ms.Eliminated, player)
+}
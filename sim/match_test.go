@@ -0,0 +1,89 @@
+package sim
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+func TestMatchUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		teams     string
+		buildings []Building
+		squads    []*Squad     // Marching when the match starts
+		captured  constants.ID // Taken by blue before the update, if set
+		ended     bool
+		winners   []constants.Player
+	}{
+		{
+			name: "goes on while both sides stand",
+			buildings: []Building{
+				{Id: 1, CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL},
+				{Id: 2, CapturedBy: constants.RED, Level: constants.HOUSE_LEVEL},
+			},
+		},
+		{
+			name: "ends with one side left",
+			buildings: []Building{
+				{Id: 1, CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL},
+				{Id: 2, CapturedBy: constants.RED, Level: constants.HOUSE_LEVEL},
+			},
+			captured: 2,
+			ended:    true,
+			winners:  []constants.Player{constants.BLUE},
+		},
+		{
+			name: "goes on against neutrals only",
+			buildings: []Building{
+				{Id: 1, CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL},
+				{Id: 2, CapturedBy: constants.NONE, Level: constants.HOUSE_LEVEL},
+			},
+		},
+		{
+			name:  "goes on with allies only",
+			teams: "BLUE,RED",
+			buildings: []Building{
+				{Id: 1, CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL},
+				{Id: 2, CapturedBy: constants.RED, Level: constants.HOUSE_LEVEL},
+			},
+		},
+		{
+			name: "counts sides starting with squads only",
+			buildings: []Building{
+				{Id: 1, CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL},
+			},
+			squads: []*Squad{{Id: 2, Owner: constants.RED, Troops: 5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams, err := ParseTeams(tt.teams)
+			if err != nil {
+				t.Fatalf("ParseTeams() error = %v", err)
+			}
+			s := New(Setup{
+				Buildings: tt.buildings,
+				Nav:       navigation.NewGrid(20, 20, constants.Tilesize),
+				Rules:     rules.Default(),
+				Teams:     teams,
+			})
+			s.Dispatch.Squads = append(s.Dispatch.Squads, tt.squads...)
+			s.Start()
+
+			if b := s.Building(tt.captured); b != nil {
+				b.CapturedBy = constants.BLUE
+			}
+			if ended := s.Match.Update(s.Buildings, s.Dispatch.Squads); ended != tt.ended {
+				t.Fatalf("Update() = %v, want %v", ended, tt.ended)
+			}
+			if tt.ended && !slices.Equal(s.Match.Winners, tt.winners) {
+				t.Errorf("Winners = %v, want %v", s.Match.Winners, tt.winners)
+			}
+		})
+	}
+}
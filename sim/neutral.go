@@ -0,0 +1,100 @@
+package sim
+
+import (
+	"cmp"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+// NeutralService runs the rebel neutrals, which every `RebelInterval` send a
+// counter-attack at the closest player-owned building in range.
+type NeutralService struct {
+	RebelInterval float64 // Seconds
+	// RebelMinGarrison is the least troops a rebel needs before it attacks.
+	RebelMinGarrison uint8
+	RebelRange       float64 // Tiles
+	cooldown         map[constants.ID]float64
+	dispatch         *DispatchService
+	rand             *rand.Rand
+	tileSize         float64
+}
+
+func NewNeutralService(dispatch *DispatchService, rand *rand.Rand, tileSize float64, r rules.NeutralRules) *NeutralService {
+	return &NeutralService{
+		RebelInterval:    r.RebelInterval,
+		RebelMinGarrison: r.RebelMinGarrison,
+		RebelRange:       r.RebelRange,
+		cooldown:         make(map[constants.ID]float64),
+		dispatch:         dispatch,
+		rand:             rand,
+		tileSize:         tileSize,
+	}
+}
+
+// Update counts down every rebel's timer and sends out the counter-attacks
+// that are due. Timers start at a random point of the interval so rebels
+// don't all strike at once.
+func (ns *NeutralService) Update(buildings []*Building, dt float64) []*Squad {
+	sent := make([]*Squad, 0)
+	for _, b := range buildings {
+		if !b.Rebel || b.CapturedBy != constants.NONE {
+			delete(ns.cooldown, b.Id)
+			continue
+		}
+
+		cooldown, ok := ns.cooldown[b.Id]
+		if !ok {
+			cooldown = ns.rand.Float64() * ns.RebelInterval
+		}
+		cooldown -= dt
+		if cooldown > 0 {
+			ns.cooldown[b.Id] = cooldown
+			continue
+		}
+		ns.cooldown[b.Id] = ns.RebelInterval
+
+		if b.Occupancy < ns.RebelMinGarrison {
+			continue
+		}
+
+		target := ns.target(b, buildings)
+		if target == nil {
+			continue
+		}
+
+		squad, err := ns.dispatch.Send(constants.NONE, b, target)
+		if err == nil {
+			sent = append(sent, squad)
+		}
+	}
+
+	return sent
+}
+
+// target returns the closest building owned by a player within the rebel's
+// range, or nil if there is none.
+func (ns *NeutralService) target(rebel *Building, buildings []*Building) *Building {
+	x, y := rebel.Entrance()
+	distance := func(b *Building) float64 {
+		bX, bY := b.Entrance()
+		return math.Hypot(bX-x, bY-y)
+	}
+
+	targets := make([]*Building, 0)
+	for _, b := range buildings {
+		if b.CapturedBy != constants.NONE && distance(b) <= ns.RebelRange*ns.tileSize {
+			targets = append(targets, b)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	return slices.MinFunc(targets, func(a, b *Building) int {
+		return cmp.Or(cmp.Compare(distance(a), distance(b)), cmp.Compare(a.Id, b.Id))
+	})
+}
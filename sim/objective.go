@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// Objective is something a campaign mission asks of the local player.
+type Objective struct {
+	// Buildings is the least buildings a win objective has to be won with.
+	Buildings   int    `json:"buildings,omitempty"`
+	Description string `json:"description,omitempty"` // Shown instead of a generated one
+	// Seconds is how long a survive objective has to be survived.
+	Seconds float64                 `json:"seconds,omitempty"`
+	Target  constants.ID            `json:"target,omitempty"` // The building to capture
+	Type    constants.ObjectiveType `json:"type"`
+}
+
+// ObjectiveService keeps track of the local player's objectives. Capture and
+// survive objectives stay done once done, and when they're all a mission needs
+// the player wins on the spot. Win objectives are only settled by how the
+// match ends.
+type ObjectiveService struct {
+	Objectives []Objective
+	Player     constants.Player
+	done       []bool
+	match      *MatchService
+	teams      Teams
+}
+
+func NewObjectiveService(match *MatchService, teams Teams, player constants.Player, objectives []Objective) *ObjectiveService {
+	return &ObjectiveService{
+		Objectives: objectives,
+		Player:     player,
+		done:       make([]bool, len(objectives)),
+		match:      match,
+		teams:      teams,
+	}
+}
+
+// Complete reports whether every objective has been met, win objectives
+// included. It's only meaningful once the match ended.
+func (obs *ObjectiveService) Complete(buildings []*Building) bool {
+	if len(obs.Objectives) == 0 {
+		return false
+	}
+
+	for i := range obs.Objectives {
+		if !obs.Done(i, buildings) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Done reports whether the i-th objective has been met so far.
+func (obs *ObjectiveService) Done(i int, buildings []*Building) bool {
+	o := obs.Objectives[i]
+	if o.Type != constants.WIN_OBJECTIVE {
+		return obs.done[i]
+	}
+
+	return slices.Contains(obs.match.Winners, obs.Player) && obs.owned(buildings) >= o.Buildings
+}
+
+// Update checks the capture and survive objectives and reports whether
+// meeting the last of them ended the match.
+func (obs *ObjectiveService) Update(tick uint64, buildings []*Building) bool {
+	if len(obs.Objectives) == 0 || obs.match.Ended || obs.match.IsEliminated(obs.Player) {
+		return false
+	}
+
+	settled := true
+	for i, o := range obs.Objectives {
+		switch o.Type {
+		case constants.CAPTURE_OBJECTIVE:
+			b := building(buildings, o.Target)
+			obs.done[i] = obs.done[i] || (b != nil && obs.teams.Allied(b.CapturedBy, obs.Player))
+		case constants.SURVIVE_OBJECTIVE:
+			obs.done[i] = obs.done[i] || float64(tick)*TickDuration >= o.Seconds
+		case constants.WIN_OBJECTIVE:
+			settled = false
+		}
+		settled = settled && obs.done[i]
+	}
+
+	if !settled {
+		return false
+	}
+
+	return obs.match.End(obs.Player)
+}
+
+// owned counts the buildings the player holds.
+func (obs *ObjectiveService) owned(buildings []*Building) int {
+	var owned int
+	for _, b := range buildings {
+		if b.CapturedBy == obs.Player {
+			owned++
+		}
+	}
+
+	return owned
+}
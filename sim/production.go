@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+type ProductionService struct {
+	// DecayRate is how many troops per second a building above its capacity
+	// loses until it's back down to it.
+	DecayRate float64
+	// SpawnBonus multiplies the production rate of `IsSpawn` buildings.
+	SpawnBonus float64
+	bus        *events.Bus
+	handicaps  Handicaps
+	// progress accumulates fractional troops between updates per building.
+	progress map[constants.ID]float64
+	upgrades *UpgradeService
+}
+
+func NewProductionService(bus *events.Bus, handicaps Handicaps, upgrades *UpgradeService, r rules.ProductionRules) *ProductionService {
+	return &ProductionService{
+		DecayRate:  r.DecayRate,
+		SpawnBonus: r.SpawnBonus,
+		bus:        bus,
+		handicaps:  handicaps,
+		progress:   make(map[constants.ID]float64),
+		upgrades:   upgrades,
+	}
+}
+
+// Update grows the garrison of every building owned by a real player toward
+// its capacity, and shrinks any garrison above capacity back down to it.
+// Neutral buildings hold the garrison the map gave them, at most regenerating
+// what they lost at their `Regen` rate. Frozen buildings don't grow at all.
+func (ps *ProductionService) Update(buildings []*Building, dt float64) {
+	for _, b := range buildings {
+		limit := b.Capacity
+		var rate float64
+		switch {
+		case b.CapturedBy == constants.NONE:
+			limit = b.Garrison
+			if b.Occupancy < limit {
+				rate = b.Regen
+			}
+		case b.Occupancy > b.Capacity:
+			rate = -ps.DecayRate
+		case b.Occupancy < b.Capacity:
+			rate = ps.upgrades.Levels[b.Level].ProductionRate * ps.handicaps.Get(b.CapturedBy).Production
+			if b.IsSpawn {
+				rate *= ps.SpawnBonus
+			}
+		}
+		if b.Frozen > 0 {
+			rate = min(rate, 0)
+		}
+		if rate == 0 {
+			delete(ps.progress, b.Id)
+			continue
+		}
+
+		progress := ps.progress[b.Id] + rate*dt
+		occupancy := b.Occupancy
+		for ; progress >= 1 && occupancy < limit; progress-- {
+			occupancy++
+		}
+		for ; progress <= -1 && occupancy > limit; progress++ {
+			occupancy--
+		}
+		ps.progress[b.Id] = progress
+
+		SetOccupancy(ps.bus, b, occupancy)
+	}
+}
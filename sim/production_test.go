@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// The default rules have houses produce 0.5 troops per second, spawns 1.5
+// times that, and garrisons over capacity decay by 1 per second.
+func TestProductionUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		building  Building
+		handicap  Handicap
+		seconds   float64
+		occupancy uint8
+	}{
+		{
+			name:      "produces",
+			building:  Building{CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL, Occupancy: 0},
+			seconds:   5,
+			occupancy: 2,
+		},
+		{
+			name:      "spawns produce faster",
+			building:  Building{CapturedBy: constants.BLUE, IsSpawn: true, Level: constants.HOUSE_LEVEL, Occupancy: 0},
+			seconds:   6,
+			occupancy: 4,
+		},
+		{
+			name:      "stops at capacity",
+			building:  Building{CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL, Occupancy: 9},
+			seconds:   10,
+			occupancy: 10,
+		},
+		{
+			name:      "decays above capacity",
+			building:  Building{CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL, Occupancy: 14},
+			seconds:   2.5,
+			occupancy: 12,
+		},
+		{
+			name:      "frozen buildings don't grow",
+			building:  Building{CapturedBy: constants.BLUE, Frozen: 100, Level: constants.HOUSE_LEVEL, Occupancy: 3},
+			seconds:   4,
+			occupancy: 3,
+		},
+		{
+			name:      "handicapped production",
+			building:  Building{CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL, Occupancy: 0},
+			handicap:  Handicap{Production: 2},
+			seconds:   4.5,
+			occupancy: 4,
+		},
+		{
+			name:      "neutrals regenerate up to their garrison",
+			building:  Building{CapturedBy: constants.NONE, Garrison: 5, Level: constants.HOUSE_LEVEL, Occupancy: 2, Regen: 1},
+			seconds:   10,
+			occupancy: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.building
+			b.Id = 1
+			s := newTestSim(t, Setup{
+				Buildings: []Building{b},
+				Handicaps: Handicaps{constants.BLUE: tt.handicap},
+			})
+
+			for range int(tt.seconds * TickRate) {
+				s.Production.Update(s.Buildings, TickDuration)
+			}
+
+			if occupancy := s.Building(1).Occupancy; occupancy != tt.occupancy {
+				t.Errorf("Update() left %d troops, want %d", occupancy, tt.occupancy)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package sim
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/rules"
+	"github.com/ehutchllew/autoarmy/spatial"
+)
+
+// The simulation always advances in steps of the same length, however fast
+// it's rendered, so the same commands always play out the same way.
+const (
+	TickRate     = 20 // Ticks per second
+	TickDuration = 1.0 / TickRate
+)
+
+// Setup is everything a match starts from.
+type Setup struct {
+	Buildings []Building
+	Handicaps Handicaps
+	Nav       *navigation.Grid
+	// NextId is handed to the first squad sent. It should start above every
+	// object ID the map uses.
+	NextId constants.ID
+	// Objectives are the local player's campaign objectives, if any.
+	Objectives []Objective
+	Player     constants.Player // The local player
+	Rules      *rules.Rules
+	Seed       uint64
+	Teams      Teams
+	// Triggers are the map's scripted events.
+	Triggers []Trigger
+}
+
+// Sim is the whole state of a match and the systems that advance it.
+type Sim struct {
+	Abilities  *AbilityService
+	Arrivals   *ArrivalService
+	Buildings  []*Building
+	Bus        *events.Bus
+	Combat     *CombatService
+	Dispatch   *DispatchService
+	Economy    *EconomyService
+	Handicaps  Handicaps
+	History    []Command // Every command applied so far, in order
+	Match      *MatchService
+	Nav        *navigation.Grid
+	Neutrals   *NeutralService
+	Objectives *ObjectiveService
+	Production *ProductionService
+	// Rand is the only source of randomness the simulation may use.
+	Rand     *rand.Rand
+	Rules    *rules.Rules
+	Seed     uint64
+	Teams    Teams
+	Tick     uint64
+	Triggers *TriggerService
+	Units    *UnitService
+	Upgrades *UpgradeService
+	Vision   *VisionService
+	index    *spatial.Grid[*Squad]
+	pcg      *rand.PCG
+	queue    []Command
+}
+
+func New(setup Setup) *Sim {
+	r := setup.Rules
+	bus := events.NewBus()
+	pcg := rand.NewPCG(setup.Seed, setup.Seed)
+
+	if setup.Handicaps == nil {
+		setup.Handicaps = make(Handicaps)
+	}
+	if setup.Teams == nil {
+		setup.Teams = make(Teams)
+	}
+
+	s := &Sim{
+		Buildings: make([]*Building, 0, len(setup.Buildings)),
+		Bus:       bus,
+		Handicaps: setup.Handicaps,
+		History:   make([]Command, 0),
+		Nav:       setup.Nav,
+		Rand:      rand.New(pcg),
+		Rules:     r,
+		Seed:      setup.Seed,
+		Teams:     setup.Teams,
+		index:     spatial.NewGrid[*Squad](constants.Tilesize * 2),
+		pcg:       pcg,
+		queue:     make([]Command, 0),
+	}
+	s.Economy = NewEconomyService(r.Economy)
+	s.Units = NewUnitService(s.Economy, r.Units)
+	s.Upgrades = NewUpgradeService(bus, s.Economy, r.Levels)
+	s.Production = NewProductionService(bus, setup.Handicaps, s.Upgrades, r.Production)
+	s.Abilities = NewAbilityService(bus, s.Economy, setup.Teams, setup.Nav.TileSize, r.Abilities)
+	s.Dispatch = NewDispatchService(s.Abilities, bus, setup.Handicaps, setup.Nav, s.Units, r.Dispatch)
+	s.Dispatch.NextId = max(s.Dispatch.NextId, setup.NextId)
+	s.Combat = NewCombatService(bus, setup.Handicaps, setup.Nav, setup.Teams, s.Units, s.Upgrades, r.Combat)
+	s.Arrivals = NewArrivalService(bus, s.Combat, s.Dispatch, setup.Teams, r.Arrival)
+	s.Match = NewMatchService(bus, setup.Teams)
+	s.Neutrals = NewNeutralService(s.Dispatch, s.Rand, setup.Nav.TileSize, r.Neutral)
+	s.Vision = NewVisionService(setup.Nav, setup.Teams, s.Upgrades, r.Vision)
+	s.Objectives = NewObjectiveService(s.Match, setup.Teams, setup.Player, slices.Clone(setup.Objectives))
+	s.Triggers = NewTriggerService(bus, s.Combat, s.Dispatch, s.Match, s.Upgrades, s.Vision, slices.Clone(setup.Triggers))
+
+	for _, b := range setup.Buildings {
+		// Buildings without a capacity of their own fall back to their level's
+		if b.Capacity == 0 {
+			b.Capacity = s.Upgrades.Levels[b.Level].Capacity
+		}
+		// The map can only turn neutral behaviors on for a building
+		if b.Regen == 0 {
+			b.Regen = r.Neutral.RegenRate
+		}
+		b.Rebel = b.Rebel || r.Neutral.Rebels
+		if b.Unit == "" {
+			b.Unit = constants.KNIGHT
+		}
+		occupancy := math.Round(float64(b.Occupancy) * setup.Handicaps.Get(b.CapturedBy).Occupancy)
+		b.Occupancy = uint8(min(occupancy, math.MaxUint8))
+		s.Buildings = append(s.Buildings, &b)
+	}
+
+	return s
+}
+
+// Start announces the map and begins the match. Anyone interested in the
+// simulation's events should subscribe before calling it.
+func (s *Sim) Start() {
+	for _, b := range s.Buildings {
+		s.Bus.Publish(events.EntitySpawned{
+			Id:    b.Id,
+			Owner: b.CapturedBy,
+			Type:  constants.BUILDING,
+		})
+	}
+	s.Match.Start(s.Buildings, s.Dispatch.Squads)
+	s.Economy.Start(s.Match.Players, s.Rules.Economy.StartingGold)
+	s.Vision.Start(s.Match.Players, s.Buildings)
+	s.Vision.Update(s.Match.Players, s.Buildings, s.Dispatch.Squads)
+}
+
+// Building returns the building with the given ID, or nil if there is none.
+func (s *Sim) Building(id constants.ID) *Building {
+	for _, b := range s.Buildings {
+		if b.Id == id {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// Enqueue queues the command to be applied at the start of the next tick.
+func (s *Sim) Enqueue(cmd Command) {
+	s.queue = append(s.queue, cmd)
+}
+
+// Step advances the match by one tick and reports whether it ended on it.
+func (s *Sim) Step() bool {
+	dt := TickDuration
+
+	queue := s.queue
+	s.queue = make([]Command, 0)
+	for _, cmd := range queue {
+		cmd.Tick = s.Tick
+		s.History = append(s.History, cmd)
+		s.apply(cmd)
+	}
+
+	for _, sq := range s.Dispatch.Squads {
+		sq.PrevX, sq.PrevY = sq.X, sq.Y
+	}
+
+	s.Abilities.Update(s.Buildings, dt)
+	s.Upgrades.Update(s.Buildings, dt)
+	s.Production.Update(s.Buildings, dt)
+	s.Economy.Update(s.Buildings, dt)
+	s.Neutrals.Update(s.Buildings, dt)
+
+	for _, sq := range s.Dispatch.Update(dt) {
+		s.index.Remove(sq)
+		if target := s.Building(sq.Target); target != nil {
+			s.Arrivals.Resolve(sq, s.Building(sq.Source), target)
+		}
+	}
+	for _, sq := range s.Dispatch.Squads {
+		s.index.Update(sq, spatial.NewRect(sq.X, sq.Y, 0, 0))
+	}
+
+	// Squads wiped out in the field are gone before the towers pick targets
+	for _, sq := range s.Combat.FieldBattles(s.Dispatch.Squads, s.index) {
+		s.Dispatch.Remove(sq)
+		s.index.Remove(sq)
+	}
+	for _, sq := range s.Combat.TowerFire(s.Buildings, s.index, dt) {
+		s.Dispatch.Remove(sq)
+		s.index.Remove(sq)
+	}
+	ended := s.Triggers.Update(s.Tick, s.Buildings, s.Dispatch.Squads)
+	ended = s.Objectives.Update(s.Tick, s.Buildings) || ended
+	s.Vision.Update(s.Match.Players, s.Buildings, s.Dispatch.Squads)
+
+	s.Tick++
+
+	return s.Match.Update(s.Buildings, s.Dispatch.Squads) || ended
+}
+
+// State returns a copy of everything that changes during the match.
+func (s *Sim) State() State {
+	rng, err := s.pcg.MarshalBinary()
+	if err != nil {
+		fmt.Printf("Unable to save random state: %v\n", err)
+	}
+
+	state := State{
+		AbilityCooldowns: cloneCooldowns(s.Abilities.Cooldowns),
+		Buildings:        make([]Building, 0, len(s.Buildings)),
+		FiredTriggers:    s.Triggers.Fired(),
+		Gold:             maps.Clone(s.Economy.Gold),
+		Hasted:           maps.Clone(s.Abilities.Hasted),
+		History:          slices.Clone(s.History),
+		NextId:           s.Dispatch.NextId,
+		Objectives:       slices.Clone(s.Objectives.done),
+		Production:       maps.Clone(s.Production.progress),
+		Rand:             rng,
+		RebelCooldowns:   maps.Clone(s.Neutrals.cooldown),
+		Reveals:          slices.Clone(s.Vision.Reveals),
+		Squads:           make([]Squad, 0, len(s.Dispatch.Squads)),
+		Tick:             s.Tick,
+		TowerCooldowns:   maps.Clone(s.Combat.towerCooldown),
+	}
+	for _, b := range s.Buildings {
+		state.Buildings = append(state.Buildings, *b)
+	}
+	for _, sq := range s.Dispatch.Squads {
+		squad := *sq
+		squad.Path = slices.Clone(sq.Path)
+		state.Squads = append(state.Squads, squad)
+	}
+
+	return state
+}
+
+// Restore puts a freshly started match into the given state. Exploration
+// isn't part of it, so every player's fog starts over from what they can see.
+func (s *Sim) Restore(state State) error {
+	if err := s.pcg.UnmarshalBinary(state.Rand); err != nil {
+		return fmt.Errorf("Unable to restore random state: %w", err)
+	}
+
+	for _, saved := range state.Buildings {
+		b := s.Building(saved.Id)
+		if b == nil {
+			return fmt.Errorf("Building (%d) is not on the map", saved.Id)
+		}
+		*b = saved
+	}
+
+	for _, sq := range s.Dispatch.Squads {
+		s.index.Remove(sq)
+	}
+	s.Dispatch.Squads = make([]*Squad, 0, len(state.Squads))
+	for _, saved := range state.Squads {
+		sq := saved
+		sq.Path = slices.Clone(saved.Path)
+		s.Dispatch.Squads = append(s.Dispatch.Squads, &sq)
+		s.index.Insert(&sq, spatial.NewRect(sq.X, sq.Y, 0, 0))
+	}
+
+	s.Abilities.Cooldowns = cloneCooldowns(state.AbilityCooldowns)
+	s.Abilities.Hasted = maps.Clone(state.Hasted)
+	if s.Abilities.Hasted == nil {
+		s.Abilities.Hasted = make(map[constants.Player]float64)
+	}
+	s.Combat.towerCooldown = maps.Clone(state.TowerCooldowns)
+	if s.Combat.towerCooldown == nil {
+		s.Combat.towerCooldown = make(map[constants.ID]float64)
+	}
+	s.Dispatch.NextId = max(s.Dispatch.NextId, state.NextId)
+	s.Economy.Gold = maps.Clone(state.Gold)
+	if s.Economy.Gold == nil {
+		s.Economy.Gold = make(map[constants.Player]float64)
+	}
+	s.Neutrals.cooldown = maps.Clone(state.RebelCooldowns)
+	if s.Neutrals.cooldown == nil {
+		s.Neutrals.cooldown = make(map[constants.ID]float64)
+	}
+	s.Triggers.fired = make(map[constants.ID]bool)
+	for _, id := range state.FiredTriggers {
+		s.Triggers.fired[id] = true
+	}
+	if len(state.Objectives) != len(s.Objectives.Objectives) {
+		return fmt.Errorf("Save has %d objectives, the match %d", len(state.Objectives), len(s.Objectives.Objectives))
+	}
+	s.Objectives.done = slices.Clone(state.Objectives)
+	s.Vision.Reveals = slices.Clone(state.Reveals)
+	s.History = slices.Clone(state.History)
+	s.Production.progress = maps.Clone(state.Production)
+	if s.Production.progress == nil {
+		s.Production.progress = make(map[constants.ID]float64)
+	}
+	s.Tick = state.Tick
+
+	s.Vision.Start(s.Match.Players, s.Buildings)
+	s.Vision.Update(s.Match.Players, s.Buildings, s.Dispatch.Squads)
+
+	return nil
+}
+
+// View returns the map as the player is allowed to see it.
+func (s *Sim) View(player constants.Player) View {
+	view := s.Vision.View(player, s.Buildings, s.Dispatch.Squads)
+	view.Gold = s.Economy.Balance(player)
+
+	return view
+}
+
+func (s *Sim) apply(cmd Command) {
+	switch cmd.Type {
+	case constants.ABILITY_COMMAND:
+		var target *Building
+		if cmd.Target != 0 {
+			if target = s.Building(cmd.Target); target == nil {
+				fmt.Printf("Unable to cast %s: building (%d) doesn't exist\n", cmd.Ability, cmd.Target)
+				return
+			}
+		}
+
+		if err := s.Abilities.Cast(cmd.Player, cmd.Ability, target, cmd.X, cmd.Y, s.Buildings); err != nil {
+			fmt.Printf("Unable to cast %s: %v\n", cmd.Ability, err)
+		}
+	case constants.SEND_COMMAND:
+		target := s.Building(cmd.Target)
+		if target == nil {
+			fmt.Printf("Unable to send troops: building (%d) doesn't exist\n", cmd.Target)
+			return
+		}
+
+		sources := make([]*Building, 0, len(cmd.Sources))
+		for _, id := range cmd.Sources {
+			if b := s.Building(id); b != nil {
+				sources = append(sources, b)
+			}
+		}
+
+		_, errs := s.Dispatch.SendMany(cmd.Player, sources, target)
+		for _, err := range errs {
+			fmt.Printf("Unable to send troops: %v\n", err)
+		}
+	case constants.UPGRADE_COMMAND:
+		b := s.Building(cmd.Target)
+		if b == nil {
+			fmt.Printf("Unable to upgrade building: building (%d) doesn't exist\n", cmd.Target)
+			return
+		}
+
+		if err := s.Upgrades.Begin(cmd.Player, b); err != nil {
+			fmt.Printf("Unable to upgrade building: %v\n", err)
+		}
+	case constants.UNIT_COMMAND:
+		b := s.Building(cmd.Target)
+		if b == nil {
+			fmt.Printf("Unable to change unit type: building (%d) doesn't exist\n", cmd.Target)
+			return
+		}
+
+		if err := s.Units.Choose(cmd.Player, b, cmd.Unit); err != nil {
+			fmt.Printf("Unable to change unit type: %v\n", err)
+		}
+	default:
+		fmt.Printf("Unknown command type %q\n", cmd.Type)
+	}
+}
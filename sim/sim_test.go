@@ -0,0 +1,99 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+// newTestSim starts a match on an open 20x20 map with the default rules.
+func newTestSim(t *testing.T, setup Setup) *Sim {
+	t.Helper()
+
+	if setup.Nav == nil {
+		setup.Nav = navigation.NewGrid(20, 20, constants.Tilesize)
+	}
+	if setup.Rules == nil {
+		setup.Rules = rules.Default()
+	}
+	s := New(setup)
+	s.Start()
+
+	return s
+}
+
+func testBuildings() []Building {
+	return []Building{
+		{Id: 1, CapturedBy: constants.BLUE, IsSpawn: true, Level: constants.CASTLE_LEVEL, Occupancy: 20, X: 160, Y: 160},
+		{Id: 2, CapturedBy: constants.BLUE, Level: constants.HOUSE_LEVEL, Occupancy: 8, X: 320, Y: 480},
+		{Id: 3, CapturedBy: constants.RED, IsSpawn: true, Level: constants.CASTLE_LEVEL, Occupancy: 20, X: 1120, Y: 160},
+		{Id: 4, CapturedBy: constants.RED, Level: constants.TOWER_LEVEL, Occupancy: 10, X: 1120, Y: 800},
+		{Id: 5, CapturedBy: constants.NONE, Garrison: 6, Level: constants.HOUSE_LEVEL, Occupancy: 6, Rebel: true, X: 640, Y: 480},
+	}
+}
+
+// TestDeterminism plays the same commands on the same ticks twice from the
+// same seed and expects both matches to end up in exactly the same state.
+func TestDeterminism(t *testing.T) {
+	commands := map[uint64][]Command{
+		5: {
+			{Player: constants.BLUE, Sources: []constants.ID{1, 2}, Target: 5, Type: constants.SEND_COMMAND},
+			{Player: constants.RED, Sources: []constants.ID{3}, Target: 5, Type: constants.SEND_COMMAND},
+		},
+		40:  {{Player: constants.RED, Sources: []constants.ID{4}, Target: 2, Type: constants.SEND_COMMAND}},
+		90:  {{Player: constants.BLUE, Sources: []constants.ID{1}, Target: 4, Type: constants.SEND_COMMAND}},
+		120: {{Player: constants.RED, Target: 4, Type: constants.UNIT_COMMAND, Unit: constants.PAWN}},
+		200: {{Ability: constants.HASTE, Player: constants.BLUE, Type: constants.ABILITY_COMMAND}},
+		260: {{Player: constants.BLUE, Sources: []constants.ID{1, 2}, Target: 3, Type: constants.SEND_COMMAND}},
+	}
+
+	play := func(seed uint64) State {
+		s := newTestSim(t, Setup{Buildings: testBuildings(), Seed: seed})
+		for s.Tick < 1200 {
+			for _, cmd := range commands[s.Tick] {
+				s.Enqueue(cmd)
+			}
+			if s.Step() {
+				break
+			}
+		}
+
+		return s.State()
+	}
+
+	first, second := play(7), play(7)
+	if len(first.History) != 7 {
+		t.Fatalf("History has %d commands, want 7", len(first.History))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Same seed and commands gave different states:\n%+v\n%+v", first, second)
+	}
+}
+
+// TestRestore resumes a match from its state halfway and expects it to play
+// out the rest just like the match it was taken from.
+func TestRestore(t *testing.T) {
+	send := Command{Player: constants.BLUE, Sources: []constants.ID{1}, Target: 5, Type: constants.SEND_COMMAND}
+
+	original := newTestSim(t, Setup{Buildings: testBuildings(), Seed: 3})
+	original.Enqueue(send)
+	for range 100 {
+		original.Step()
+	}
+
+	resumed := newTestSim(t, Setup{Buildings: testBuildings(), Seed: 3})
+	if err := resumed.Restore(original.State()); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	for range 400 {
+		original.Step()
+		resumed.Step()
+	}
+	if !reflect.DeepEqual(original.State(), resumed.State()) {
+		t.Errorf("Resumed match diverged from the original")
+	}
+}
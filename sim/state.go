@@ -0,0 +1,101 @@
+package sim
+
+import "github.com/ehutchllew/autoarmy/constants"
+
+// Building is the gameplay state of a building. Its position is the
+// bottom-center of its footprint, where it meets the ground, so it doesn't
+// move when the building changes size on an upgrade.
+type Building struct {
+	Capacity   uint8            `json:"capacity"`
+	CapturedBy constants.Player `json:"capturedBy"`
+	// Frozen is how many seconds the building's production stays stopped.
+	Frozen float64 `json:"frozen"`
+	// Garrison is what the building holds while neutral, from the map.
+	// Neutral buildings never regenerate past it and never decay.
+	Garrison  uint8                   `json:"garrison"`
+	Id        constants.ID            `json:"id"`
+	IsSpawn   bool                    `json:"isSpawn"`
+	Level     constants.BuildingLevel `json:"level"`
+	Occupancy uint8                   `json:"occupancy"`
+	// Rebel neutrals send counter-attacks at nearby players.
+	Rebel bool `json:"rebel"`
+	// Regen is how many troops per second the building regains toward its
+	// `Garrison` while neutral.
+	Regen float64 `json:"regen"`
+	// Unit is the type of troops the building produces and garrisons.
+	Unit constants.UnitType `json:"unit"`
+	// UpgradeProgress is the completed fraction (0-1) of an in-flight
+	// upgrade and is only meaningful while `Upgrading` is set.
+	UpgradeProgress float64 `json:"upgradeProgress"`
+	Upgrading       bool    `json:"upgrading"`
+	X               float64 `json:"x"`
+	Y               float64 `json:"y"`
+}
+
+// Squad is a group of troops of a single unit type marching from one building
+// to another. Its position is the center of the formation at its feet.
+type Squad struct {
+	// Delay is how many seconds the squad waits at its source before it
+	// starts marching.
+	Delay float64          `json:"delay"`
+	Id    constants.ID     `json:"id"`
+	Owner constants.Player `json:"owner"`
+	Path  []Point          `json:"path"` // Remaining waypoints, the last being the target
+	// PrevX and PrevY are where the squad was at the start of the last tick,
+	// for rendering between ticks.
+	PrevX float64 `json:"prevX"`
+	PrevY float64 `json:"prevY"`
+	// Returning is set on overflow marching back to its source, which is
+	// never bounced again.
+	Returning bool               `json:"returning"`
+	Source    constants.ID       `json:"source"`
+	Speed     float64            `json:"speed"` // Pixels per second
+	Target    constants.ID       `json:"target"`
+	Troops    uint8              `json:"troops"`
+	Unit      constants.UnitType `json:"unit"`
+	X         float64            `json:"x"`
+	Y         float64            `json:"y"`
+}
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Base returns a point just inside the building's footprint, on the ground it
+// stands on.
+func (b *Building) Base() (float64, float64) {
+	return b.X, b.Y - 1
+}
+
+// Entrance returns the point in front of the building where squads leave from
+// and arrive at.
+func (b *Building) Entrance() (float64, float64) {
+	return b.X, b.Y + constants.Tilesize/4
+}
+
+// State is everything about a match that changes as it's played. Together
+// with the map, the rules and the seed it's enough to resume the match.
+type State struct {
+	// AbilityCooldowns is the seconds left on each player's abilities.
+	AbilityCooldowns map[constants.Player]map[constants.AbilityType]float64 `json:"abilityCooldowns"`
+	Buildings        []Building                                             `json:"buildings"`
+	// FiredTriggers are the map triggers that already went off.
+	FiredTriggers []constants.ID               `json:"firedTriggers"`
+	Gold          map[constants.Player]float64 `json:"gold"`
+	Hasted        map[constants.Player]float64 `json:"hasted"`
+	// History is every command applied so far, which is what replays are
+	// made of.
+	History []Command    `json:"history"`
+	NextId  constants.ID `json:"nextId"`
+	// Objectives is which of the local player's objectives are done.
+	Objectives []bool `json:"objectives"`
+	// Production is the fractional troops accumulated per building.
+	Production     map[constants.ID]float64 `json:"production"`
+	Rand           []byte                   `json:"rand"`
+	RebelCooldowns map[constants.ID]float64 `json:"rebelCooldowns"`
+	Reveals        []Reveal                 `json:"reveals"`
+	Squads         []Squad                  `json:"squads"`
+	Tick           uint64                   `json:"tick"`
+	TowerCooldowns map[constants.ID]float64 `json:"towerCooldowns"`
+}
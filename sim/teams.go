@@ -0,0 +1,53 @@
+package sim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// Teams puts players on numbered teams. Allies reinforce each other's
+// buildings, share vision, never fight and win together. Players left out
+// play on their own.
+type Teams map[constants.Player]int
+
+// ParseTeams reads teams written as comma separated players, one team after
+// another separated by semicolons, e.g. "BLUE,GREEN;RED,YELLOW".
+func ParseTeams(s string) (Teams, error) {
+	teams := make(Teams)
+	if strings.TrimSpace(s) == "" {
+		return teams, nil
+	}
+
+	for i, team := range strings.Split(s, ";") {
+		for _, name := range strings.Split(team, ",") {
+			player := constants.Player(strings.ToUpper(strings.TrimSpace(name)))
+			if !slices.Contains(constants.Players, player) {
+				return nil, fmt.Errorf("%q is not a player", name)
+			}
+			if _, ok := teams[player]; ok {
+				return nil, fmt.Errorf("%s is on more than one team", player)
+			}
+			teams[player] = i + 1
+		}
+	}
+
+	return teams, nil
+}
+
+// Allied reports whether the two players are on the same side. Every player
+// is allied with themselves, and neutrals with nobody else.
+func (t Teams) Allied(a, b constants.Player) bool {
+	if a == b {
+		return true
+	}
+	if a == constants.NONE || b == constants.NONE {
+		return false
+	}
+
+	aTeam, aOk := t[a]
+	bTeam, bOk := t[b]
+	return aOk && bOk && aTeam == bTeam
+}
@@ -0,0 +1,231 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+)
+
+// Trigger is a scripted event from the map. It fires its actions once, on
+// the first tick all of its conditions hold. A trigger either covers an area
+// of the map, is linked to a building, or both.
+type Trigger struct {
+	Actions TriggerActions `json:"actions"`
+	// Building is the building the trigger is linked to, 0 if none.
+	Building   constants.ID      `json:"building"`
+	Conditions TriggerConditions `json:"conditions"`
+	// Height and Width are the size of the area the trigger covers from its
+	// top-left corner at X, Y. Both are 0 unless it's an area trigger.
+	Height float64      `json:"height"`
+	Id     constants.ID `json:"id"`
+	Width  float64      `json:"width"`
+	X      float64      `json:"x"`
+	Y      float64      `json:"y"`
+}
+
+// TriggerConditions are what has to hold for a trigger to fire. Unset
+// conditions always hold, so a trigger without any fires on the first tick.
+type TriggerConditions struct {
+	// CapturedBy holds while the linked building belongs to the player.
+	CapturedBy constants.Player `json:"capturedBy,omitempty"`
+	// EnteredBy holds while a squad of the player is inside the area.
+	EnteredBy constants.Player `json:"enteredBy,omitempty"`
+	Tick      uint64           `json:"tick,omitempty"` // Holds from this tick on
+	// TroopsBelow holds while `TroopsOf` has fewer troops than this in all
+	// of their garrisons and squads together.
+	TroopsBelow int              `json:"troopsBelow,omitempty"`
+	TroopsOf    constants.Player `json:"troopsOf,omitempty"`
+}
+
+// TriggerActions are what a trigger does when it fires. Unset actions are
+// skipped.
+type TriggerActions struct {
+	// EndMatch ends the match with the player's team as the winners, or with
+	// nobody winning for NONE.
+	EndMatch constants.Player `json:"endMatch,omitempty"`
+	Message  string           `json:"message,omitempty"`
+	// Reveal lifts the fog over the area, or around the linked building, for
+	// the player and their allies for the rest of the match.
+	Reveal constants.Player `json:"reveal,omitempty"`
+	// SetOwner hands the linked building over to the player.
+	SetOwner constants.Player `json:"setOwner,omitempty"`
+	// SpawnTroops sends a new squad of `SpawnOwner` toward `SpawnTarget`
+	// from the linked building, or from the middle of the area.
+	SpawnOwner  constants.Player   `json:"spawnOwner,omitempty"`
+	SpawnTarget constants.ID       `json:"spawnTarget,omitempty"`
+	SpawnTroops uint8              `json:"spawnTroops,omitempty"`
+	SpawnUnit   constants.UnitType `json:"spawnUnit,omitempty"`
+}
+
+// TriggerService runs the map's triggers every tick.
+type TriggerService struct {
+	Triggers []Trigger
+	bus      *events.Bus
+	combat   *CombatService
+	dispatch *DispatchService
+	fired    map[constants.ID]bool
+	match    *MatchService
+	upgrades *UpgradeService
+	vision   *VisionService
+}
+
+func NewTriggerService(bus *events.Bus, combat *CombatService, dispatch *DispatchService, match *MatchService, upgrades *UpgradeService, vision *VisionService, triggers []Trigger) *TriggerService {
+	return &TriggerService{
+		Triggers: triggers,
+		bus:      bus,
+		combat:   combat,
+		dispatch: dispatch,
+		fired:    make(map[constants.ID]bool),
+		match:    match,
+		upgrades: upgrades,
+		vision:   vision,
+	}
+}
+
+// Fired returns the IDs of the triggers that have fired, in order.
+func (ts *TriggerService) Fired() []constants.ID {
+	fired := make([]constants.ID, 0, len(ts.fired))
+	for id := range ts.fired {
+		fired = append(fired, id)
+	}
+	slices.Sort(fired)
+
+	return fired
+}
+
+// Update fires every trigger whose conditions hold on this tick, in map
+// order, and reports whether one of them ended the match.
+func (ts *TriggerService) Update(tick uint64, buildings []*Building, squads []*Squad) bool {
+	var ended bool
+	for i := range ts.Triggers {
+		t := &ts.Triggers[i]
+		if ts.fired[t.Id] || !ts.holds(t, tick, buildings, squads) {
+			continue
+		}
+
+		ts.fired[t.Id] = true
+		ended = ts.fire(t, buildings) || ended
+	}
+
+	return ended
+}
+
+func (ts *TriggerService) holds(t *Trigger, tick uint64, buildings []*Building, squads []*Squad) bool {
+	c := t.Conditions
+	if tick < c.Tick {
+		return false
+	}
+
+	if c.CapturedBy != "" {
+		b := building(buildings, t.Building)
+		if b == nil || b.CapturedBy != c.CapturedBy {
+			return false
+		}
+	}
+
+	if c.EnteredBy != "" {
+		entered := slices.ContainsFunc(squads, func(s *Squad) bool {
+			return s.Owner == c.EnteredBy && s.Delay <= 0 && t.contains(s.X, s.Y)
+		})
+		if !entered {
+			return false
+		}
+	}
+
+	if c.TroopsOf != "" {
+		var troops int
+		for _, b := range buildings {
+			if b.CapturedBy == c.TroopsOf {
+				troops += int(b.Occupancy)
+			}
+		}
+		for _, s := range squads {
+			if s.Owner == c.TroopsOf {
+				troops += int(s.Troops)
+			}
+		}
+		if troops >= c.TroopsBelow {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fire carries out the trigger's actions and reports whether it ended the
+// match.
+func (ts *TriggerService) fire(t *Trigger, buildings []*Building) bool {
+	a := t.Actions
+	linked := building(buildings, t.Building)
+
+	if a.SetOwner != "" {
+		if linked != nil {
+			ts.combat.Capture(linked, a.SetOwner)
+		} else {
+			fmt.Printf("Unable to set owner: trigger (%d) isn't linked to a building\n", t.Id)
+		}
+	}
+
+	if a.SpawnTroops > 0 {
+		target := building(buildings, a.SpawnTarget)
+		unit := a.SpawnUnit
+		if unit == "" {
+			unit = constants.KNIGHT
+		}
+
+		var err error
+		switch {
+		case target == nil:
+			err = fmt.Errorf("building (%d) doesn't exist", a.SpawnTarget)
+		case linked != nil:
+			x, y := linked.Entrance()
+			_, err = ts.dispatch.SpawnAt(a.SpawnOwner, linked.Id, x, y, target, unit, a.SpawnTroops)
+		default:
+			_, err = ts.dispatch.SpawnAt(a.SpawnOwner, 0, t.X+t.Width/2, t.Y+t.Height/2, target, unit, a.SpawnTroops)
+		}
+		if err != nil {
+			fmt.Printf("Unable to spawn troops: %v\n", err)
+		}
+	}
+
+	if a.Reveal != "" {
+		switch {
+		case t.Width > 0 && t.Height > 0:
+			ts.vision.Reveal(a.Reveal, t.X+t.Width/2, t.Y+t.Height/2, math.Hypot(t.Width, t.Height)/2)
+		case linked != nil:
+			x, y := linked.Base()
+			radius := ts.upgrades.Levels[linked.Level].Vision * ts.vision.nav.TileSize
+			ts.vision.Reveal(a.Reveal, x, y, radius)
+		}
+	}
+
+	ts.bus.Publish(events.TriggerFired{
+		Message: a.Message,
+		Trigger: t.Id,
+	})
+
+	if a.EndMatch != "" {
+		return ts.match.End(a.EndMatch)
+	}
+
+	return false
+}
+
+// contains reports whether the point is inside the trigger's area. Triggers
+// without an area contain nothing.
+func (t *Trigger) contains(x, y float64) bool {
+	return x >= t.X && x < t.X+t.Width && y >= t.Y && y < t.Y+t.Height
+}
+
+func building(buildings []*Building, id constants.ID) *Building {
+	for _, b := range buildings {
+		if b.Id == id {
+			return b
+		}
+	}
+
+	return nil
+}
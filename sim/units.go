@@ -0,0 +1,76 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+// UnitService knows what every unit type is worth and lets players choose
+// which type their buildings produce.
+type UnitService struct {
+	Units   map[constants.UnitType]rules.UnitStats
+	economy *EconomyService
+}
+
+func NewUnitService(economy *EconomyService, units map[constants.UnitType]rules.UnitStats) *UnitService {
+	return &UnitService{
+		Units:   units,
+		economy: economy,
+	}
+}
+
+// Choose switches the building to producing the given unit type, paying its
+// gold cost. The garrison already inside retrains as the new type.
+func (us *UnitService) Choose(player constants.Player, b *Building, unit constants.UnitType) error {
+	if b.CapturedBy != player {
+		return fmt.Errorf("Building (%d) is not owned by %s", b.Id, player)
+	}
+	if b.Unit == unit {
+		return fmt.Errorf("Building (%d) already produces %s", b.Id, unit)
+	}
+
+	stats, ok := us.Units[unit]
+	if !ok {
+		return fmt.Errorf("Unknown unit type %q", unit)
+	}
+	if err := us.economy.Spend(player, stats.GoldCost); err != nil {
+		return fmt.Errorf("Building (%d) can't switch to %s: %w", b.Id, unit, err)
+	}
+
+	b.Unit = unit
+	return nil
+}
+
+// Counter returns the multiplier the counter table gives `unit` against
+// `against`.
+func (us *UnitService) Counter(unit, against constants.UnitType) float64 {
+	if counter, ok := us.Units[unit].Counters[against]; ok {
+		return counter
+	}
+
+	return 1
+}
+
+// Strength is how much a single troop of `unit` is worth fighting `against`.
+// It combines attack, defense and the counter table so two sides can be
+// compared by troops times strength alone.
+func (us *UnitService) Strength(unit, against constants.UnitType) float64 {
+	stats, ok := us.Units[unit]
+	if !ok {
+		return 1
+	}
+
+	return stats.Attack * stats.Defense * us.Counter(unit, against)
+}
+
+// Speed returns how fast a squad of the unit marches, as a multiple of the
+// base squad speed.
+func (us *UnitService) Speed(unit constants.UnitType) float64 {
+	if stats, ok := us.Units[unit]; ok {
+		return stats.Speed
+	}
+
+	return 1
+}
@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/events"
+	"github.com/ehutchllew/autoarmy/rules"
+)
+
+type UpgradeService struct {
+	Levels  map[constants.BuildingLevel]rules.LevelStats
+	bus     *events.Bus
+	economy *EconomyService
+}
+
+func NewUpgradeService(bus *events.Bus, economy *EconomyService, levels map[constants.BuildingLevel]rules.LevelStats) *UpgradeService {
+	return &UpgradeService{
+		Levels:  levels,
+		bus:     bus,
+		economy: economy,
+	}
+}
+
+// Begin spends the troops and gold required for the next level and starts the
+// upgrade timer. The building keeps its current level until `Update` completes it.
+func (us *UpgradeService) Begin(player constants.Player, b *Building) error {
+	if b.CapturedBy != player {
+		return fmt.Errorf("Building (%d) is not owned by %s", b.Id, player)
+	}
+	if b.Upgrading {
+		return fmt.Errorf("Building (%d) is already upgrading", b.Id)
+	}
+
+	next, ok := us.Levels[b.Level+1]
+	if !ok {
+		return fmt.Errorf("Building (%d) is already at max level", b.Id)
+	}
+	if b.Occupancy < next.UpgradeCost {
+		return fmt.Errorf("Building (%d) needs %d troops to upgrade, has %d", b.Id, next.UpgradeCost, b.Occupancy)
+	}
+	if err := us.economy.Spend(player, next.GoldCost); err != nil {
+		return fmt.Errorf("Building (%d) can't be upgraded: %w", b.Id, err)
+	}
+
+	SetOccupancy(us.bus, b, b.Occupancy-next.UpgradeCost)
+	b.Upgrading = true
+	b.UpgradeProgress = 0
+
+	return nil
+}
+
+// Interrupt cancels an in-flight upgrade, e.g. when the building comes under
+// attack. The troops and gold spent on it are not refunded.
+func (us *UpgradeService) Interrupt(b *Building) {
+	b.Upgrading = false
+	b.UpgradeProgress = 0
+}
+
+// Update advances every in-flight upgrade by `dt` seconds and returns the
+// buildings that reached their next level this update so the caller can swap
+// their sprites.
+func (us *UpgradeService) Update(buildings []*Building, dt float64) []*Building {
+	upgraded := make([]*Building, 0)
+	for _, b := range buildings {
+		if !b.Upgrading {
+			continue
+		}
+
+		next := us.Levels[b.Level+1]
+		if next.UpgradeTime > 0 {
+			b.UpgradeProgress += dt / next.UpgradeTime
+		} else {
+			b.UpgradeProgress = 1
+		}
+		if b.UpgradeProgress < 1 {
+			continue
+		}
+
+		// Keep whatever offset the map gave the building's capacity
+		cur := us.Levels[b.Level]
+		b.Capacity += next.Capacity - cur.Capacity
+		b.Level++
+		b.Upgrading = false
+		b.UpgradeProgress = 0
+
+		upgraded = append(upgraded, b)
+	}
+
+	return upgraded
+}
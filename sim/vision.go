@@ -0,0 +1,185 @@
+package sim
+
+import (
+	"github.com/ehutchllew/autoarmy/constants"
+	"github.com/ehutchllew/autoarmy/navigation"
+	"github.com/ehutchllew/autoarmy/rules"
+	"github.com/ehutchllew/autoarmy/visibility"
+)
+
+// VisionService keeps each player's fog of war up to date from the buildings
+// and squads they and their allies own, and is the only way anything other
+// than rendering for the owner should learn about the state of the map.
+type VisionService struct {
+	// ElevationBonus is the extra sight radius, in tiles, per elevation level
+	// a building or squad stands on.
+	ElevationBonus float64
+	Fog            *visibility.Fog
+	// Reveals are areas kept in sight of their player for good.
+	Reveals     []Reveal
+	SquadRadius float64 // Tiles
+	nav         *navigation.Grid
+	teams       Teams
+	upgrades    *UpgradeService
+}
+
+// Reveal is an area of the map lifted out of the fog for a player and their
+// allies.
+type Reveal struct {
+	Player constants.Player `json:"player"`
+	Radius float64          `json:"radius"` // Pixels
+	X      float64          `json:"x"`
+	Y      float64          `json:"y"`
+}
+
+// SquadSighting is what a player can see of someone else's squad.
+type SquadSighting struct {
+	Id     constants.ID
+	Owner  constants.Player
+	Troops uint8
+	Unit   constants.UnitType
+	X, Y   float64
+}
+
+// View is everything a player is allowed to know about the map right now.
+type View struct {
+	Buildings []visibility.BuildingSnapshot
+	Gold      int // The player's own balance
+	Squads    []SquadSighting
+}
+
+func NewVisionService(nav *navigation.Grid, teams Teams, upgrades *UpgradeService, r rules.VisionRules) *VisionService {
+	return &VisionService{
+		ElevationBonus: r.ElevationBonus,
+		Fog:            visibility.NewFog(nav.Width, nav.Height, nav.TileSize),
+		Reveals:        make([]Reveal, 0),
+		SquadRadius:    r.SquadRadius,
+		nav:            nav,
+		teams:          teams,
+		upgrades:       upgrades,
+	}
+}
+
+// BuildingVisible reports whether the player can currently see the building.
+func (vs *VisionService) BuildingVisible(player constants.Player, b *Building) bool {
+	if b.CapturedBy != constants.NONE && vs.teams.Allied(b.CapturedBy, player) {
+		return true
+	}
+
+	x, y := b.Base()
+	return vs.Fog.Visible(player, x, y)
+}
+
+// SquadVisible reports whether the player can currently see the squad.
+func (vs *VisionService) SquadVisible(player constants.Player, s *Squad) bool {
+	return vs.teams.Allied(s.Owner, player) || vs.Fog.Visible(player, s.X, s.Y)
+}
+
+// Reveal keeps the circle around (x, y) in sight of the player and their
+// allies from the next update on.
+func (vs *VisionService) Reveal(player constants.Player, x, y, radius float64) {
+	vs.Reveals = append(vs.Reveals, Reveal{
+		Player: player,
+		Radius: radius,
+		X:      x,
+		Y:      y,
+	})
+}
+
+// Start gives every player the state of the map as it's laid out, which is
+// public knowledge before the first unit moves.
+func (vs *VisionService) Start(players []constants.Player, buildings []*Building) {
+	for _, p := range players {
+		for _, b := range buildings {
+			vs.Fog.Remember(p, snapshot(b))
+		}
+	}
+}
+
+// Update recomputes each player's sight and refreshes what they remember of
+// every building they can see.
+func (vs *VisionService) Update(players []constants.Player, buildings []*Building, squads []*Squad) {
+	for _, p := range players {
+		sources := make([]visibility.Source, 0)
+		for _, b := range buildings {
+			if b.CapturedBy == constants.NONE || !vs.teams.Allied(b.CapturedBy, p) {
+				continue
+			}
+
+			x, y := b.Base()
+			sources = append(sources, vs.source(x, y, vs.upgrades.Levels[b.Level].Vision))
+		}
+		for _, s := range squads {
+			if vs.teams.Allied(s.Owner, p) {
+				sources = append(sources, vs.source(s.X, s.Y, vs.SquadRadius))
+			}
+		}
+		for _, r := range vs.Reveals {
+			if vs.teams.Allied(r.Player, p) {
+				sources = append(sources, visibility.Source{
+					Radius: r.Radius,
+					X:      r.X,
+					Y:      r.Y,
+				})
+			}
+		}
+		vs.Fog.Update(p, sources)
+
+		for _, b := range buildings {
+			if vs.BuildingVisible(p, b) {
+				vs.Fog.Remember(p, snapshot(b))
+			}
+		}
+	}
+}
+
+// View returns the map as the player is allowed to see it: the last known
+// state of every building, and only the squads currently in sight.
+func (vs *VisionService) View(player constants.Player, buildings []*Building, squads []*Squad) View {
+	view := View{
+		Buildings: make([]visibility.BuildingSnapshot, 0, len(buildings)),
+		Squads:    make([]SquadSighting, 0),
+	}
+	for _, b := range buildings {
+		if known, ok := vs.Fog.LastKnown(player, b.Id); ok {
+			view.Buildings = append(view.Buildings, known)
+		}
+	}
+	for _, s := range squads {
+		if !vs.SquadVisible(player, s) {
+			continue
+		}
+
+		view.Squads = append(view.Squads, SquadSighting{
+			Id:     s.Id,
+			Owner:  s.Owner,
+			Troops: s.Troops,
+			Unit:   s.Unit,
+			X:      s.X,
+			Y:      s.Y,
+		})
+	}
+
+	return view
+}
+
+func (vs *VisionService) source(x, y, radius float64) visibility.Source {
+	radius += vs.ElevationBonus * float64(vs.nav.ElevationAt(x, y))
+
+	return visibility.Source{
+		Radius: radius * vs.nav.TileSize,
+		X:      x,
+		Y:      y,
+	}
+}
+
+func snapshot(b *Building) visibility.BuildingSnapshot {
+	return visibility.BuildingSnapshot{
+		Capacity:  b.Capacity,
+		Id:        b.Id,
+		Level:     b.Level,
+		Occupancy: b.Occupancy,
+		Owner:     b.CapturedBy,
+		Unit:      b.Unit,
+	}
+}
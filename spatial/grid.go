@@ -0,0 +1,204 @@
+package spatial
+
+import "math"
+
+type Rect struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+func NewRect(x, y, w, h float64) Rect {
+	return Rect{
+		MinX: x,
+		MinY: y,
+		MaxX: x + w,
+		MaxY: y + h,
+	}
+}
+
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.MinX && x <= r.MaxX && y >= r.MinY && y <= r.MaxY
+}
+
+func (r Rect) Intersects(o Rect) bool {
+	return r.MinX <= o.MaxX && r.MaxX >= o.MinX && r.MinY <= o.MaxY && r.MaxY >= o.MinY
+}
+
+// IntersectsCircle reports whether any part of the rect lies within `radius`
+// of the given point.
+func (r Rect) IntersectsCircle(x, y, radius float64) bool {
+	nX := math.Max(r.MinX, math.Min(x, r.MaxX))
+	nY := math.Max(r.MinY, math.Min(y, r.MaxY))
+	dX, dY := x-nX, y-nY
+
+	return dX*dX+dY*dY <= radius*radius
+}
+
+type cell struct {
+	x, y int
+}
+
+// cellRange is the inclusive span of cells a rect overlaps.
+type cellRange struct {
+	min, max cell
+}
+
+// Grid is a uniform-grid spatial index over world-space bounds. Each key is
+// bucketed into every cell its bounds overlap, so queries only need to look
+// at the handful of cells around the area of interest instead of every key.
+//
+// Query results are ordered by cell (row-major) and then by insertion order
+// within a cell, so the same sequence of calls always yields the same order.
+type Grid[K comparable] struct {
+	bounds   map[K]Rect
+	cellSize float64
+	cells    map[cell][]K
+}
+
+func NewGrid[K comparable](cellSize float64) *Grid[K] {
+	return &Grid[K]{
+		bounds:   make(map[K]Rect),
+		cellSize: cellSize,
+		cells:    make(map[cell][]K),
+	}
+}
+
+// Bounds returns the rect the key was last indexed with.
+func (g *Grid[K]) Bounds(key K) (Rect, bool) {
+	r, ok := g.bounds[key]
+	return r, ok
+}
+
+// Insert indexes the key with the given bounds, replacing any bounds it was
+// previously indexed with.
+func (g *Grid[K]) Insert(key K, r Rect) {
+	if _, ok := g.bounds[key]; ok {
+		g.Update(key, r)
+		return
+	}
+
+	g.bounds[key] = r
+	g.addToCells(key, g.cellsFor(r))
+}
+
+func (g *Grid[K]) Len() int {
+	return len(g.bounds)
+}
+
+func (g *Grid[K]) QueryPoint(x, y float64) []K {
+	c := g.cellAt(x, y)
+	found := make([]K, 0)
+	for _, key := range g.cells[c] {
+		if g.bounds[key].Contains(x, y) {
+			found = append(found, key)
+		}
+	}
+
+	return found
+}
+
+func (g *Grid[K]) QueryRadius(x, y, radius float64) []K {
+	area := Rect{x - radius, y - radius, x + radius, y + radius}
+
+	return g.query(area, func(r Rect) bool {
+		return r.IntersectsCircle(x, y, radius)
+	})
+}
+
+func (g *Grid[K]) QueryRect(area Rect) []K {
+	return g.query(area, area.Intersects)
+}
+
+func (g *Grid[K]) Remove(key K) {
+	r, ok := g.bounds[key]
+	if !ok {
+		return
+	}
+
+	g.removeFromCells(key, g.cellsFor(r))
+	delete(g.bounds, key)
+}
+
+// Update moves the key to its new bounds. Keys are only re-bucketed when the
+// set of cells they overlap changes, which for a unit walking across the map
+// is a small fraction of its updates.
+func (g *Grid[K]) Update(key K, r Rect) {
+	old, ok := g.bounds[key]
+	if !ok {
+		g.Insert(key, r)
+		return
+	}
+
+	g.bounds[key] = r
+	oldCells, newCells := g.cellsFor(old), g.cellsFor(r)
+	if oldCells == newCells {
+		return
+	}
+
+	g.removeFromCells(key, oldCells)
+	g.addToCells(key, newCells)
+}
+
+func (g *Grid[K]) addToCells(key K, cr cellRange) {
+	for y := cr.min.y; y <= cr.max.y; y++ {
+		for x := cr.min.x; x <= cr.max.x; x++ {
+			c := cell{x, y}
+			g.cells[c] = append(g.cells[c], key)
+		}
+	}
+}
+
+func (g *Grid[K]) cellAt(x, y float64) cell {
+	return cell{
+		x: int(math.Floor(x / g.cellSize)),
+		y: int(math.Floor(y / g.cellSize)),
+	}
+}
+
+func (g *Grid[K]) cellsFor(r Rect) cellRange {
+	return cellRange{
+		min: g.cellAt(r.MinX, r.MinY),
+		max: g.cellAt(r.MaxX, r.MaxY),
+	}
+}
+
+func (g *Grid[K]) query(area Rect, match func(Rect) bool) []K {
+	cr := g.cellsFor(area)
+	seen := make(map[K]struct{})
+	found := make([]K, 0)
+	for y := cr.min.y; y <= cr.max.y; y++ {
+		for x := cr.min.x; x <= cr.max.x; x++ {
+			for _, key := range g.cells[cell{x, y}] {
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+
+				if match(g.bounds[key]) {
+					found = append(found, key)
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+func (g *Grid[K]) removeFromCells(key K, cr cellRange) {
+	for y := cr.min.y; y <= cr.max.y; y++ {
+		for x := cr.min.x; x <= cr.max.x; x++ {
+			c := cell{x, y}
+			keys := g.cells[c]
+			for i, k := range keys {
+				if k == key {
+					keys = append(keys[:i], keys[i+1:]...)
+					break
+				}
+			}
+			if len(keys) == 0 {
+				delete(g.cells, c)
+			} else {
+				g.cells[c] = keys
+			}
+		}
+	}
+}
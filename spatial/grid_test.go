@@ -0,0 +1,134 @@
+package spatial
+
+import (
+	"slices"
+	"testing"
+)
+
+const testCellSize = 64
+
+func TestGridQueryRadius(t *testing.T) {
+	tests := []struct {
+		name   string
+		index  func(g *Grid[string])
+		x, y   float64
+		radius float64
+		want   []string
+	}{
+		{
+			name: "finds inserted keys in range",
+			index: func(g *Grid[string]) {
+				g.Insert("near", NewRect(10, 10, 0, 0))
+				g.Insert("far", NewRect(500, 500, 0, 0))
+			},
+			x: 0, y: 0, radius: 20,
+			want: []string{"near"},
+		},
+		{
+			name: "reaches across a cell edge",
+			index: func(g *Grid[string]) {
+				g.Insert("edge", NewRect(70, 10, 0, 0))
+			},
+			x: 60, y: 10, radius: 10,
+			want: []string{"edge"},
+		},
+		{
+			name: "stops short of a cell edge",
+			index: func(g *Grid[string]) {
+				g.Insert("edge", NewRect(70, 10, 0, 0))
+			},
+			x: 60, y: 10, radius: 9.9,
+		},
+		{
+			name: "includes keys right on the radius",
+			index: func(g *Grid[string]) {
+				g.Insert("boundary", NewRect(testCellSize, 10, 0, 0))
+			},
+			x: 60, y: 10, radius: 4,
+			want: []string{"boundary"},
+		},
+		{
+			name: "finds keys spanning cells once",
+			index: func(g *Grid[string]) {
+				g.Insert("wide", NewRect(50, 10, 100, 100))
+			},
+			x: 100, y: 60, radius: 80,
+			want: []string{"wide"},
+		},
+		{
+			name: "follows keys moved across cells",
+			index: func(g *Grid[string]) {
+				g.Insert("mover", NewRect(10, 10, 0, 0))
+				g.Update("mover", NewRect(200, 10, 0, 0))
+			},
+			x: 200, y: 10, radius: 5,
+			want: []string{"mover"},
+		},
+		{
+			name: "forgets where moved keys were",
+			index: func(g *Grid[string]) {
+				g.Insert("mover", NewRect(10, 10, 0, 0))
+				g.Update("mover", NewRect(200, 10, 0, 0))
+			},
+			x: 10, y: 10, radius: 5,
+		},
+		{
+			name: "reinserting moves the key",
+			index: func(g *Grid[string]) {
+				g.Insert("mover", NewRect(10, 10, 0, 0))
+				g.Insert("mover", NewRect(200, 10, 0, 0))
+			},
+			x: 200, y: 10, radius: 5,
+			want: []string{"mover"},
+		},
+		{
+			name: "skips removed keys",
+			index: func(g *Grid[string]) {
+				g.Insert("kept", NewRect(10, 10, 0, 0))
+				g.Insert("removed", NewRect(12, 10, 0, 0))
+				g.Remove("removed")
+			},
+			x: 10, y: 10, radius: 5,
+			want: []string{"kept"},
+		},
+		{
+			name: "orders results by cell then insertion",
+			index: func(g *Grid[string]) {
+				g.Insert("second cell", NewRect(70, 10, 0, 0))
+				g.Insert("first cell b", NewRect(20, 10, 0, 0))
+				g.Insert("first cell a", NewRect(10, 10, 0, 0))
+			},
+			x: 40, y: 10, radius: 40,
+			want: []string{"first cell b", "first cell a", "second cell"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGrid[string](testCellSize)
+			tt.index(g)
+
+			if got := g.QueryRadius(tt.x, tt.y, tt.radius); !slices.Equal(got, tt.want) {
+				t.Errorf("QueryRadius() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGridRemove(t *testing.T) {
+	g := NewGrid[string](testCellSize)
+	g.Insert("a", NewRect(10, 10, 100, 0))
+	g.Insert("b", NewRect(10, 10, 0, 0))
+	g.Remove("a")
+	g.Remove("missing")
+
+	if g.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", g.Len())
+	}
+	if _, ok := g.Bounds("a"); ok {
+		t.Errorf("Bounds() still has the removed key")
+	}
+	if got := g.QueryPoint(100, 10); len(got) != 0 {
+		t.Errorf("QueryPoint() = %v in a cell only the removed key spanned", got)
+	}
+}
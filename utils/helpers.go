@@ -13,6 +13,28 @@ func SafeConvertBool(val any) bool {
 	return val.(bool)
 }
 
+func SafeConvertFloat64(val any) (float64, error) {
+	if val == nil {
+		return 0, nil // Return zero value of float64
+	}
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse string as float64: %v", err)
+		}
+		return parsed, nil
+	default:
+		return 0, fmt.Errorf("unsupported type for float64 conversion: %T", val)
+	}
+}
+
 func SafeConvertString(val any) string {
 	if val == nil {
 		return "" // Return zero value of string
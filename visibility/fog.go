@@ -0,0 +1,119 @@
+package visibility
+
+import (
+	"math"
+
+	"github.com/ehutchllew/autoarmy/constants"
+)
+
+// Source is something that lets its owner see the cells around it.
+type Source struct {
+	Radius float64 // World units
+	X, Y   float64
+}
+
+// BuildingSnapshot is what a player knows about a building, as of the last
+// time they saw it.
+type BuildingSnapshot struct {
+	Capacity  uint8
+	Id        constants.ID
+	Level     constants.BuildingLevel
+	Occupancy uint8
+	Owner     constants.Player
+	Unit      constants.UnitType
+}
+
+// Fog tracks, per player, which cells are currently in sight, which have been
+// seen before, and the last state they saw each building in.
+type Fog struct {
+	Height    int
+	TileSize  float64
+	Width     int
+	explored  map[constants.Player][]bool
+	lastKnown map[constants.Player]map[constants.ID]BuildingSnapshot
+	visible   map[constants.Player][]bool
+}
+
+func NewFog(width, height int, tileSize float64) *Fog {
+	return &Fog{
+		Height:    height,
+		TileSize:  tileSize,
+		Width:     width,
+		explored:  make(map[constants.Player][]bool),
+		lastKnown: make(map[constants.Player]map[constants.ID]BuildingSnapshot),
+		visible:   make(map[constants.Player][]bool),
+	}
+}
+
+// Explored reports whether the player has ever seen the given world point.
+func (f *Fog) Explored(player constants.Player, wX, wY float64) bool {
+	return f.lookup(f.explored[player], wX, wY)
+}
+
+// LastKnown returns the player's most recent snapshot of the building.
+func (f *Fog) LastKnown(player constants.Player, id constants.ID) (BuildingSnapshot, bool) {
+	snapshot, ok := f.lastKnown[player][id]
+	return snapshot, ok
+}
+
+// Remember records what the player currently knows about a building.
+func (f *Fog) Remember(player constants.Player, snapshot BuildingSnapshot) {
+	known, ok := f.lastKnown[player]
+	if !ok {
+		known = make(map[constants.ID]BuildingSnapshot)
+		f.lastKnown[player] = known
+	}
+
+	known[snapshot.Id] = snapshot
+}
+
+// Update replaces the player's current sight with what the given sources can
+// see, and marks it explored.
+func (f *Fog) Update(player constants.Player, sources []Source) {
+	visible, ok := f.visible[player]
+	if !ok {
+		visible = make([]bool, f.Width*f.Height)
+		f.visible[player] = visible
+		f.explored[player] = make([]bool, f.Width*f.Height)
+	}
+	explored := f.explored[player]
+	clear(visible)
+
+	for _, s := range sources {
+		x0 := max(int(math.Floor((s.X-s.Radius)/f.TileSize)), 0)
+		y0 := max(int(math.Floor((s.Y-s.Radius)/f.TileSize)), 0)
+		x1 := min(int(math.Floor((s.X+s.Radius)/f.TileSize)), f.Width-1)
+		y1 := min(int(math.Floor((s.Y+s.Radius)/f.TileSize)), f.Height-1)
+
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				cX, cY := (float64(x)+0.5)*f.TileSize, (float64(y)+0.5)*f.TileSize
+				if math.Hypot(cX-s.X, cY-s.Y) > s.Radius {
+					continue
+				}
+
+				visible[y*f.Width+x] = true
+				explored[y*f.Width+x] = true
+			}
+		}
+	}
+}
+
+// Visible reports whether the given world point is currently in the player's
+// sight.
+func (f *Fog) Visible(player constants.Player, wX, wY float64) bool {
+	return f.lookup(f.visible[player], wX, wY)
+}
+
+func (f *Fog) lookup(cells []bool, wX, wY float64) bool {
+	if cells == nil {
+		return false
+	}
+
+	x, y := int(math.Floor(wX/f.TileSize)), int(math.Floor(wY/f.TileSize))
+	if x < 0 || y < 0 || x >= f.Width || y >= f.Height {
+		return false
+	}
+
+	return cells[y*f.Width+x]
+}